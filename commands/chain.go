@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/contract"
+	"github.com/spf13/cobra"
+)
+
+func cmdHistory() *cobra.Command {
+	var (
+		contractDir string
+		limit       int
+		offset      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history [contract-hash]",
+		Short: "List the blocks of a smart contract token chain",
+		Long:  "List the blocks of a smart contract token chain, oldest first, with the deployment and execution data they record",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 0 || offset < 0 {
+				return usageErrorf("--limit and --offset must not be negative")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			contractHash, err := resolveContractHash(cfg, firstArg(args), contractDir)
+			if err != nil {
+				return err
+			}
+
+			blocks, err := contract.History(cmd.Context(), cfg, contractHash, false)
+			if err != nil {
+				return fmt.Errorf("failed to get contract history: %w", err)
+			}
+
+			total := len(blocks)
+			start, end := min(offset, total), total
+			if limit > 0 {
+				end = min(start+limit, total)
+			}
+			page := blocks[start:end]
+
+			result := struct {
+				ContractHash string            `json:"contract_hash"`
+				Total        int               `json:"total"`
+				Offset       int               `json:"offset"`
+				Blocks       []*contract.Block `json:"blocks"`
+			}{
+				ContractHash: contractHash,
+				Total:        total,
+				Offset:       start,
+				Blocks:       page,
+			}
+			return printResult(cmd, result, func() {
+				for _, block := range page {
+					printBlock(cmd, block)
+				}
+				if len(page) == 0 {
+					cmd.Printf("No blocks to show, the token chain has %d blocks\n", total)
+					return
+				}
+				cmd.Printf("Showing blocks %d to %d of %d\n", start+1, end, total)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Contract project whose latest deployment is used when no hash is given")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of blocks to show, 0 for all")
+	cmd.Flags().IntVar(&offset, "offset", 0, "Number of blocks to skip")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdState() *cobra.Command {
+	var (
+		contractDir string
+		latest      bool
+		blockNo     uint64
+		local       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "state [contract-hash]",
+		Short: "Show the state of a smart contract",
+		Long:  "Show the data recorded by the latest block of a smart contract token chain, or by the block given by --block. With --local, show the local state of the contract project kept between local executions",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if local {
+				if len(args) > 0 || cmd.Flags().Changed("block") || cmd.Flags().Changed("latest") {
+					return usageErrorf("--local cannot be used with a contract hash, --latest or --block")
+				}
+				if contractDir == "" {
+					return usageErrorf("--contract-dir is required with --local")
+				}
+
+				state, err := contract.ReadLocalState(contractDir)
+				if err != nil {
+					return err
+				}
+				return printResult(cmd, state, func() {
+					content, _ := json.MarshalIndent(state, "", "  ")
+					cmd.Println(string(content))
+				})
+			}
+
+			atBlock := cmd.Flags().Changed("block")
+			if atBlock && cmd.Flags().Changed("latest") && latest {
+				return usageErrorf("--latest and --block cannot be used together")
+			}
+			if !atBlock && !latest {
+				return usageErrorf("--block is required with --latest=false")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			contractHash, err := resolveContractHash(cfg, firstArg(args), contractDir)
+			if err != nil {
+				return err
+			}
+
+			blocks, err := contract.History(cmd.Context(), cfg, contractHash, !atBlock)
+			if err != nil {
+				return fmt.Errorf("failed to get contract state: %w", err)
+			}
+
+			block := blocks[len(blocks)-1]
+			if atBlock {
+				block = nil
+				for _, b := range blocks {
+					if b.BlockNo == blockNo {
+						block = b
+					}
+				}
+				if block == nil {
+					return usageErrorf("block %d not found, the token chain has %d blocks", blockNo, len(blocks))
+				}
+			}
+
+			return printResult(cmd, block, func() {
+				printBlock(cmd, block)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Contract project whose latest deployment is used when no hash is given")
+	cmd.Flags().BoolVar(&latest, "latest", true, "Show the state recorded by the latest block")
+	cmd.Flags().Uint64Var(&blockNo, "block", 0, "Show the state recorded by the given block number")
+	cmd.Flags().BoolVar(&local, "local", false, "Show the local state of the contract project given by --contract-dir")
+
+	cmd.AddCommand(
+		cmdStateReset(),
+		cmdStateSnapshot(),
+		cmdStateRestore(),
+	)
+
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdSync() *cobra.Command {
+	var (
+		contractDir string
+		save        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync [contract-hash]",
+		Short: "Reconstruct the state of a smart contract locally",
+		Long:  "Replay the message of every block of a smart contract token chain through the local WASM module of the contract project, starting from its initial state, and report the reconstructed state and the blocks whose local execution fails",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			contractHash, err := resolveContractHash(cfg, firstArg(args), contractDir)
+			if err != nil {
+				return err
+			}
+
+			printProgress(cmd, "Replaying the token chain...")
+			result, err := contract.Sync(cmd.Context(), cfg, contractHash, contractDir, save)
+			if err != nil {
+				return fmt.Errorf("sync failed: %w", err)
+			}
+
+			if err := printResult(cmd, result, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "BLOCK\tSTATUS\tDETAIL")
+				for _, block := range result.Blocks {
+					detail := block.ContractResult
+					if block.Error != "" {
+						detail = strings.ReplaceAll(strings.TrimSpace(block.Error), "\n", " ")
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\n", block.BlockNo, block.Status, detail)
+				}
+				w.Flush()
+
+				state, _ := json.MarshalIndent(result.State, "", "  ")
+				cmd.Printf("Reconstructed state:\n%s\n", state)
+				if result.Saved {
+					cmd.Println("Local state replaced by the reconstructed state")
+				}
+			}); err != nil {
+				return err
+			}
+
+			if result.Diverged > 0 {
+				return fmt.Errorf("%w: %d of %d blocks failed locally", contract.ErrDiverged, result.Diverged, len(result.Blocks))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.Flags().BoolVar(&save, "save", false, "Replace the local state of the contract project by the reconstructed state, if no block diverged")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// printBlock prints a block with its data pretty-printed
+func printBlock(cmd *cobra.Command, block *contract.Block) {
+	cmd.Printf("Block %d (%s)\n", block.BlockNo, block.BlockID)
+
+	var data bytes.Buffer
+	if err := json.Indent(&data, block.Data, "  ", "  "); err != nil {
+		data.Reset()
+		data.Write(block.Data)
+	}
+	if strings.TrimSpace(data.String()) == "null" {
+		cmd.Println("  (no data)")
+		return
+	}
+	cmd.Printf("  %s\n", data.String())
+}
+
+// resolveContractHash returns the given contract hash, or the hash of the
+// latest deployment of the contract project on the active network
+func resolveContractHash(cfg *config.Config, contractHash string, contractDir string) (string, error) {
+	if contractHash != "" {
+		return contractHash, nil
+	}
+	if contractDir == "" {
+		return "", usageErrorf("a contract hash or --contract-dir is required")
+	}
+
+	deployment, err := contract.LatestDeployment(contractDir, cfg.NetworkName)
+	if err != nil {
+		return "", fmt.Errorf("contract hash is not set: %w", err)
+	}
+	return deployment.ContractHash, nil
+}
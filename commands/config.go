@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"text/tabwriter"
+	"time"
 
+	"github.com/pelletier/go-toml/v2"
 	"github.com/rubixchain/rubix-nexus/config"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +23,10 @@ func configCommands() *cobra.Command {
 	cmd.AddCommand(
 		cmdInit(),
 		cmdValidate(),
+		cmdConfigGet(),
+		cmdConfigSet(),
+		cmdConfigShow(),
+		networkCommands(),
 	)
 
 	return cmd
@@ -31,13 +39,18 @@ func cmdInit() *cobra.Command {
 		Long:  "Initialize default configuration in $HOME/.rubix-nexus/config.toml",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := config.GenerateConfig(flagHomeDir); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to initialize config: %v\n", err)
-				return nil
+				return fmt.Errorf("failed to initialize config: %w", err)
 			}
 
-			configPath := filepath.Join(flagHomeDir, ".rubix-nexus", "config.toml")
-			cmd.Printf("Configuration initialized at %v", configPath)
-			return nil
+			configPath := config.FilePath(flagHomeDir)
+			result := struct {
+				ConfigPath string `json:"config_path"`
+			}{
+				ConfigPath: configPath,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Configuration initialized at %v", configPath)
+			})
 		},
 	}
 	cmd.SilenceUsage = true
@@ -45,19 +58,340 @@ func cmdInit() *cobra.Command {
 }
 
 func cmdValidate() *cobra.Command {
+	var (
+		online  bool
+		timeout time.Duration
+	)
+
 	cmd := &cobra.Command{
 		Use:   "validate",
 		Short: "Validate configuration file",
-		Long:  "Validate the configuration file at $HOME/.rubix-nexus/config.toml",
+		Long:  "Validate the configuration file at $HOME/.rubix-nexus/config.toml. With --online, also check that the deployer node is reachable and serves the smart contract endpoints",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := config.ValidateConfig(flagHomeDir); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: invalid configuration: %v\n", err)
-				return nil
+			if !online {
+				if err := config.ValidateConfig(flagHomeDir); err != nil {
+					return err
+				}
+
+				result := struct {
+					Valid      bool   `json:"valid"`
+					ConfigPath string `json:"config_path"`
+				}{
+					Valid:      true,
+					ConfigPath: config.FilePath(flagHomeDir),
+				}
+				return printResult(cmd, result, func() {
+					cmd.Println("Configuration is valid")
+				})
+			}
+
+			fileCheck := &config.Check{Name: "config file", Status: config.CheckPass, Detail: config.FilePath(flagHomeDir)}
+			if err := config.ValidateConfig(flagHomeDir); errors.Is(err, config.ErrConfigNotFound) {
+				fileCheck.Status, fileCheck.Detail = config.CheckSkip, "not found, using defaults and overrides"
+			} else if err != nil {
+				fileCheck.Status, fileCheck.Detail, fileCheck.Err = config.CheckFail, err.Error(), err
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			checks := append([]*config.Check{fileCheck}, config.CheckNode(ctx, cfg)...)
+
+			var failed []*config.Check
+			for _, check := range checks {
+				if check.Status == config.CheckFail {
+					failed = append(failed, check)
+				}
+			}
+
+			result := struct {
+				Valid      bool            `json:"valid"`
+				ConfigPath string          `json:"config_path"`
+				Network    string          `json:"network"`
+				Checks     []*config.Check `json:"checks"`
+			}{
+				Valid:      len(failed) == 0,
+				ConfigPath: config.FilePath(flagHomeDir),
+				Network:    cfg.NetworkName,
+				Checks:     checks,
+			}
+			if err := printResult(cmd, result, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+				for _, check := range checks {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Status, check.Detail)
+				}
+				w.Flush()
+			}); err != nil {
+				return err
+			}
+
+			if len(failed) > 0 {
+				return fmt.Errorf("%d of %d checks failed, first failure: %s: %w", len(failed), len(checks), failed[0].Name, failed[0].Err)
 			}
-			cmd.Println("Configuration is valid")
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&online, "online", false, "Contact the deployer node and check its smart contract endpoints")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of the online checks")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdConfigGet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: "Print a configuration value",
+		Long:  "Print the value of a dotted configuration key, such as networks.localnet.deployer_node_url",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			value, err := config.GetKey(flagHomeDir, key)
+			if err != nil {
+				return fmt.Errorf("failed to get %s: %w", key, err)
+			}
+
+			result := struct {
+				Key   string      `json:"key"`
+				Value interface{} `json:"value"`
+			}{
+				Key:   key,
+				Value: value,
+			}
+			return printResult(cmd, result, func() {
+				printTOMLValue(cmd, value)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdConfigSet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Set a configuration value",
+		Long:  "Validate and set the value of a dotted configuration key, preserving comments and unknown keys of the configuration file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			if err := config.SetKey(flagHomeDir, key, value); err != nil {
+				return fmt.Errorf("failed to set %s: %w", key, err)
+			}
+
+			result := struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			}{
+				Key:   key,
+				Value: value,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("%s set to %s\n", key, value)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdConfigShow() *cobra.Command {
+	var resolved bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the configuration",
+		Long:  "Print the configuration read from $HOME/.rubix-nexus/config.toml, or with --resolved, the effective values and where they come from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if resolved {
+				cfg, err := loadConfig()
+				if err != nil {
+					return err
+				}
+
+				return printResult(cmd, cfg.Resolved, func() {
+					w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "KEY\tVALUE\tSOURCE\tORIGIN")
+					for _, value := range cfg.Resolved {
+						fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", value.Key, value.Value, value.Source, value.Origin)
+					}
+					w.Flush()
+				})
+			}
+
+			cfg, err := config.ReadConfig(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			return printResult(cmd, cfg, func() {
+				printTOMLValue(cmd, cfg)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&resolved, "resolved", false, "Show the effective values after applying environment variables and flags")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// printTOMLValue prints scalar values as is and tables in TOML
+func printTOMLValue(cmd *cobra.Command, value interface{}) {
+	switch value.(type) {
+	case string, bool, int, float64:
+		cmd.Println(value)
+	default:
+		content, err := toml.Marshal(value)
+		if err != nil {
+			cmd.Println(value)
+			return
+		}
+		cmd.Print(string(content))
+	}
+}
+
+func networkCommands() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "Network profile related sub-commands",
+		Long:  "Manage the [networks.<name>] profiles of the configuration file",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		cmdNetworkAdd(),
+		cmdNetworkRemove(),
+		cmdNetworkList(),
+		cmdNetworkUse(),
+	)
+
+	return cmd
+}
+
+func cmdNetworkAdd() *cobra.Command {
+	var (
+		nodeURL    string
+		setDefault bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a network profile",
+		Long:  "Add a network profile with the URL of its deployer node",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if nodeURL == "" {
+				return usageErrorf("--node-url is required")
+			}
+
+			if err := config.AddNetwork(flagHomeDir, name, config.NetworkConfig{DeployerNodeURL: nodeURL}); err != nil {
+				return fmt.Errorf("failed to add network: %w", err)
+			}
+			if setDefault {
+				if err := config.UseNetwork(flagHomeDir, name); err != nil {
+					return fmt.Errorf("failed to set default network: %w", err)
+				}
+			}
+
+			result := config.NetworkProfile{
+				Name:          name,
+				Default:       setDefault,
+				NetworkConfig: config.NetworkConfig{DeployerNodeURL: nodeURL},
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Network '%s' added\n", name)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&nodeURL, "node-url", "", "URL of the deployer node of the network")
+	cmd.Flags().BoolVar(&setDefault, "default", false, "Make the network the default network")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdNetworkRemove() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a network profile",
+		Long:  "Remove a network profile. The default network cannot be removed",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := config.RemoveNetwork(flagHomeDir, name); err != nil {
+				return fmt.Errorf("failed to remove network: %w", err)
+			}
+
+			result := struct {
+				Name string `json:"name"`
+			}{
+				Name: name,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Network '%s' removed\n", name)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdNetworkList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List network profiles",
+		Long:  "List the network profiles of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := config.ListNetworks(flagHomeDir)
+			if err != nil {
+				return fmt.Errorf("failed to list networks: %w", err)
+			}
+
+			return printResult(cmd, profiles, func() {
+				for _, profile := range profiles {
+					marker := " "
+					if profile.Default {
+						marker = "*"
+					}
+					cmd.Printf("%s %s\t%s\n", marker, profile.Name, profile.DeployerNodeURL)
+				}
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdNetworkUse() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use [name]",
+		Short: "Set the default network",
+		Long:  "Set the network profile used when --network is not provided",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if err := config.UseNetwork(flagHomeDir, name); err != nil {
+				return fmt.Errorf("failed to set default network: %w", err)
+			}
+
+			result := struct {
+				DefaultNetwork string `json:"default_network"`
+			}{
+				DefaultNetwork: name,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Default network set to '%s'\n", name)
+			})
+		},
+	}
 	cmd.SilenceUsage = true
 	return cmd
 }
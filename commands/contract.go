@@ -2,6 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/rubixchain/rubix-nexus/contract"
 	"github.com/spf13/cobra"
@@ -17,8 +21,13 @@ func contractCommands() *cobra.Command {
 
 	cmd.AddCommand(
 		cmdBootstrap(),
+		cmdBuild(),
 		cmdDeploy(),
 		cmdExecute(),
+		cmdHistory(),
+		cmdState(),
+		cmdSync(),
+		cmdTest(),
 	)
 
 	return cmd
@@ -33,67 +42,143 @@ func cmdBootstrap() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			contractName := args[0]
 			if err := contract.Bootstrap(contractName); err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to bootstrap contract: %v\n", err)
-				return nil
+				return fmt.Errorf("failed to bootstrap contract: %w", err)
 			}
-			cmd.Printf("Contract project '%s' bootstrapped successfully\n", contractName)
-			return nil
+
+			result := struct {
+				ContractName string `json:"contract_name"`
+			}{
+				ContractName: contractName,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Contract project '%s' bootstrapped successfully\n", contractName)
+			})
 		},
 	}
 	cmd.SilenceUsage = true
 	return cmd
 }
 
+func cmdBuild() *cobra.Command {
+	var (
+		contractDir string
+		opts        contract.BuildOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build a smart contract",
+		Long:  "Build a smart contract to WASM and copy the binary to the artifacts directory of the Rust project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			printProgress(cmd, "Building contract...")
+			artifact, err := contract.Build(contractDir, opts)
+			if err != nil {
+				return fmt.Errorf("build failed: %w", err)
+			}
+
+			if opts.Optimize && !artifact.Optimized {
+				printProgress(cmd, "wasm-opt not found, the WASM binary was not optimized")
+			}
+			result := struct {
+				*contract.Artifact
+				Path string `json:"path"`
+			}{
+				Artifact: artifact,
+				Path:     artifact.Path,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Contract built at %s\n", artifact.Path)
+				cmd.Printf("Size: %d bytes\n", artifact.Size)
+				cmd.Printf("SHA-256: %s\n", artifact.SHA256)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.Flags().BoolVar(&opts.Release, "release", false, "Build with the release profile")
+	cmd.Flags().StringSliceVar(&opts.Features, "features", nil, "Comma separated list of cargo features to enable")
+	cmd.Flags().BoolVar(&opts.Optimize, "optimize", false, "Optimize the WASM binary with wasm-opt, if installed")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
 func cmdDeploy() *cobra.Command {
 	var (
 		contractDir      string
 		deployerDid      string
 		deployAmt        float64
+		prebuilt         contract.Prebuilt
 	)
 
 	cmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploy a smart contract",
-		Long:  "Deploy a smart contract from a Rust project directory",
+		Long:  "Deploy a smart contract from a Rust project directory, or from a prebuilt WASM binary with its source and initial state, without building it",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if contractDir == "" {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --contract-dir is required\n")
-				return nil
-			}
-			if deployerDid == "" {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --deployer-did is required\n")
-				return nil
+			var prebuiltFiles *contract.Prebuilt
+			if cmd.Flags().Changed("wasm") || cmd.Flags().Changed("source") || cmd.Flags().Changed("state") {
+				if prebuilt.WasmPath == "" || prebuilt.SourcePath == "" || prebuilt.StatePath == "" {
+					return usageErrorf("--wasm, --source and --state must be set together")
+				}
+				prebuiltFiles = &prebuilt
+			} else if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
 			}
 
 			// Print stage messages
 			onStage := func(stage contract.DeploymentStage) {
 				switch stage {
 				case contract.StageBuild:
-					cmd.Println("Building contract...")
+					printProgress(cmd, "Building contract...")
 				case contract.StageGenerate:
-					cmd.Println("Generating smart contract...")
+					printProgress(cmd, "Generating smart contract...")
 				case contract.StageDeploy:
-					cmd.Println("Deploying smart contract...")
+					printProgress(cmd, "Deploying smart contract...")
 				}
 			}
 
-			result, err := contract.Deploy(contractDir, flagHomeDir, deployerDid, deployAmt, onStage)
+			cfg, err := loadConfig()
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: deployment failed: %v\n", err)
-				return nil
+				return err
 			}
 
-			if result.Success {
-				cmd.Printf("Contract deployed successfully with hash: %s\n", result.ContractHash)
-			} else {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: deployment failed: %s\n", result.Message)
+			deployerDid, err := resolveDID(cfg, deployerDid, "--deployer-did")
+			if err != nil {
+				return err
 			}
-			return nil
+
+			password, err := resolvePassword(cmd, deployerDid, false)
+			if err != nil {
+				return err
+			}
+
+			result, err := contract.Deploy(cmd.Context(), cfg, contractDir, prebuiltFiles, deployerDid, deployAmt, password, onStage)
+			if err != nil {
+				return fmt.Errorf("deployment failed: %w", err)
+			}
+
+			if !result.Success {
+				return fmt.Errorf("deployment failed: %s", result.Message)
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Contract deployed successfully with hash: %s\n", result.ContractHash)
+				if result.Warning != "" {
+					cmd.Printf("Warning: %s\n", result.Warning)
+				}
+			})
 		},
 	}
 
-	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
-	cmd.Flags().StringVar(&deployerDid, "deployer-did", "", "Contract Deployer DID")
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project (optional with --wasm, to record the deployment)")
+	cmd.Flags().StringVar(&prebuilt.WasmPath, "wasm", "", "Prebuilt WASM binary to deploy instead of building the contract project")
+	cmd.Flags().StringVar(&prebuilt.SourcePath, "source", "", "lib.rs source of the prebuilt WASM binary")
+	cmd.Flags().StringVar(&prebuilt.StatePath, "state", "", "JSON initial state of the prebuilt contract")
+	cmd.Flags().StringVar(&deployerDid, "deployer-did", "", "Contract Deployer DID or alias (defaults to default_did of the network)")
 	cmd.Flags().Float64Var(&deployAmt, "deploy-amt", 0.001, "RBT amount to deploy the contract")
 	cmd.SilenceUsage = true
 	return cmd
@@ -105,46 +190,185 @@ func cmdExecute() *cobra.Command {
 		executorDid     string
 		contractDir     string
 		contractMsgFile string
+		dryRun          bool
+		skipPreflight   bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "execute",
 		Short: "Execute a deployed smart contract",
-		Long:  "Execute a deployed smart contract with a JSON message",
+		Long:  "Execute a deployed smart contract with a JSON message. The message is first run through the local WASM module, and the execution is not submitted if the contract returns an error. With --dry-run, the message is only run through the local WASM module, without contacting the node. Host functions run locally, unless mocked or disabled in the [host_functions] section of the configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if contractHash == "" {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --contract-hash is required\n")
-				return nil
-			}
 			if contractMsgFile == "" {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --contract-msg-file is required\n")
-				return nil
+				return usageErrorf("--contract-msg-file is required")
 			}
 			if contractDir == "" {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: --contract-dir is required\n")
+				return usageErrorf("--contract-dir is required")
 			}
 
-			cmd.Println("Executing smart contract...")
-			result, err := contract.Execute(contractHash, executorDid, flagHomeDir, contractDir, contractMsgFile)
+			cfg, err := loadConfig()
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: execution failed: %v\n", err)
-				return nil
+				return err
 			}
 
-			if result.Success {
-				cmd.Printf("Contract Result: %v\n", result.ContractResult)
-			} else {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: execution failed: %s\n", result.Message)
+			if dryRun {
+				result, err := contract.DryRun(contractDir, contractMsgFile, cfg.HostFunctions)
+				if err != nil {
+					return fmt.Errorf("dry run failed: %w", err)
+				}
+				return printResult(cmd, result, func() {
+					cmd.Printf("Contract Result (dry run): %v\n", result.ContractResult)
+				})
 			}
-			return nil
+
+			executorDid, err := resolveDID(cfg, executorDid, "--executor-did")
+			if err != nil {
+				return err
+			}
+
+			// The preflight runs the artifact of the contract directory in
+			// place of the deployed contract, which must be the same binary
+			if contractHash == "" {
+				deployment, err := contract.LatestDeployment(contractDir, cfg.NetworkName)
+				if err != nil {
+					return fmt.Errorf("contract hash is not set: %w", err)
+				}
+				if !skipPreflight {
+					if err := deployment.VerifyArtifact(contractDir); err != nil {
+						return err
+					}
+				}
+				contractHash = deployment.ContractHash
+			}
+
+			password, err := resolvePassword(cmd, executorDid, false)
+			if err != nil {
+				return err
+			}
+
+			printProgress(cmd, "Executing smart contract...")
+			result, err := contract.Execute(cmd.Context(), cfg, contractHash, executorDid, password, contractDir, contractMsgFile, !skipPreflight)
+			if err != nil {
+				// A failed preflight is reported in JSON output, the error explains it in text
+				if result != nil {
+					if errPrint := printResult(cmd, result, func() {}); errPrint != nil {
+						return errPrint
+					}
+				}
+				return fmt.Errorf("execution failed: %w", err)
+			}
+
+			if !result.Success {
+				return fmt.Errorf("execution failed: %s", result.Message)
+			}
+			return printResult(cmd, result, func() {
+				if result.ContractResult == "" {
+					cmd.Println(result.Message)
+					return
+				}
+				cmd.Printf("Contract Result: %v\n", result.ContractResult)
+			})
 		},
 	}
 
-	cmd.Flags().StringVar(&contractHash, "contract-hash", "", "Hash of the deployed contract")
-	cmd.Flags().StringVar(&executorDid, "executor-did", "", "Executor DID")
+	cmd.Flags().StringVar(&contractHash, "contract-hash", "", "Hash of the deployed contract (defaults to the latest deployment of the contract directory on the network)")
+	cmd.Flags().StringVar(&executorDid, "executor-did", "", "Executor DID or alias (defaults to default_did of the network)")
 	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
 	cmd.Flags().StringVar(&contractMsgFile, "contract-msg-file", "", "File containing the JSON message for contract execution")
+	cmd.Flags().BoolVar(&skipPreflight, "skip-preflight", false, "Submit the execution without running the message through the local WASM module first")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Run the message through the local WASM module only, without contacting the node")
 	
 	cmd.SilenceUsage = true
 	return cmd
 }
+
+func cmdTest() *cobra.Command {
+	var (
+		contractDir string
+		junitFile   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test [fixture-file...]",
+		Short: "Test a smart contract with JSON fixtures",
+		Long:  "Run the JSON fixtures of a contract project, tests/*.json by default, through its local WASM module and report the fixtures whose result or error is not the expected one",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			files := args
+			if len(files) == 0 {
+				if files, err = contract.FixtureFiles(contractDir); err != nil {
+					return err
+				}
+			}
+
+			report, err := contract.RunTests(contractDir, files, cfg.HostFunctions)
+			if err != nil {
+				return fmt.Errorf("tests failed to run: %w", err)
+			}
+
+			if junitFile != "" {
+				if err := writeJUnitReport(junitFile, report, filepath.Base(filepath.Clean(contractDir))); err != nil {
+					return err
+				}
+			}
+
+			if err := printResult(cmd, report, func() {
+				for _, test := range report.Tests {
+					if test.Passed {
+						cmd.Printf("PASS  %s (%s)\n", test.Name, test.Duration.Round(time.Millisecond))
+						continue
+					}
+					cmd.Printf("FAIL  %s (%s): %s\n", test.Name, test.Duration.Round(time.Millisecond), test.Failure)
+					if test.Diff != "" {
+						cmd.Print(indent(test.Diff, "      "))
+					}
+				}
+				cmd.Printf("%d passed, %d failed\n", report.Passed, report.Failed)
+			}); err != nil {
+				return err
+			}
+
+			if report.Failed > 0 {
+				return fmt.Errorf("%w: %d of %d fixtures failed", contract.ErrTestsFailed, report.Failed, len(report.Tests))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.Flags().StringVar(&junitFile, "junit", "", "Write a JUnit XML report to the file")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// writeJUnitReport writes the test report as JUnit XML to the file
+func writeJUnitReport(path string, report *contract.TestReport, suite string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := report.WriteJUnit(file, suite); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// indent prefixes every line of text
+func indent(text string, prefix string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
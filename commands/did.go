@@ -2,7 +2,10 @@ package commands
 
 import (
 	"fmt"
+	"text/tabwriter"
+	"time"
 
+	"github.com/rubixchain/rubix-nexus/config"
 	"github.com/rubixchain/rubix-nexus/did"
 	"github.com/spf13/cobra"
 )
@@ -17,32 +20,442 @@ func didCommands() *cobra.Command {
 
 	cmd.AddCommand(
 		cmdCreate(),
+		cmdDIDList(),
+		cmdDIDShow(),
+		didAliasCommands(),
+		cmdDIDBalance(),
+		cmdDIDTokens(),
+		cmdDIDFaucet(),
+		cmdDIDTransfer(),
 	)
 
 	return cmd
 }
 
 func cmdCreate() *cobra.Command {
-	var flagLocalnet bool
+	var (
+		flagLocalnet     bool
+		flagSavePassword bool
+		flagType         string
+		flagMnemonicFile string
+		flagChildPath    int
+		flagMasterDID    string
+		flagAlias        string
+		flagTokens       int
+	)
 
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Create a new DID",
-		Long:  "Create a new DID",
+		Long:  "Create a new DID of the given type. Lite DIDs can be recovered from a mnemonic file and derived at a child path, child DIDs are created under a master DID",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			did, err := did.CreateDID(flagHomeDir, flagLocalnet)
+			didType, err := did.ParseType(flagType)
+			if err != nil {
+				return err
+			}
+
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+			if flagAlias != "" {
+				if err := registry.CheckAlias(flagAlias, ""); err != nil {
+					return err
+				}
+			}
+
+			masterDID := flagMasterDID
+			if masterDID != "" {
+				masterDID = registry.Resolve(masterDID)
+			}
+			if cmd.Flags().Changed("tokens") && !flagLocalnet {
+				return usageErrorf("--tokens can only be used with --localnet")
+			}
+			opts := did.CreateOptions{
+				Type:         didType,
+				MnemonicFile: flagMnemonicFile,
+				ChildPath:    flagChildPath,
+				MasterDID:    masterDID,
+				Localnet:     flagLocalnet,
+				TestTokens:   flagTokens,
+			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			password, err := resolvePassword(cmd, "", true)
 			if err != nil {
-				fmt.Fprintf(cmd.ErrOrStderr(), "Error: failed to create DID: %v\n", err)
-				return nil
+				return err
 			}
-			cmd.Printf("DID created successfully: %s\n", did)
-			return nil
+
+			result, createErr := did.CreateDID(cmd.Context(), cfg, password, opts)
+			if result == nil {
+				return fmt.Errorf("failed to create DID: %w", createErr)
+			}
+
+			err = registry.Add(&did.Record{
+				DID:       result.DID,
+				PeerID:    result.PeerID,
+				Type:      result.Type,
+				Network:   cfg.NetworkName,
+				CreatedAt: time.Now().UTC(),
+				Alias:     flagAlias,
+			})
+			if err != nil {
+				return fmt.Errorf("DID %s created but not recorded: %w", result.DID, err)
+			}
+
+			if flagSavePassword {
+				if err := savePassword(cmd, result.DID, password); err != nil {
+					return fmt.Errorf("DID %s created but its password was not stored: %w", result.DID, err)
+				}
+			}
+			if createErr != nil {
+				return createErr
+			}
+
+			return printResult(cmd, result, func() {
+				cmd.Printf("DID created successfully: %s (%s)\n", result.DID, result.Type)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVar(&flagLocalnet, "localnet", false, "It indicates whether the deployer node is running on a localnet setup")
+	cmd.Flags().IntVar(&flagTokens, "tokens", 1, "Number of test RBT tokens generated with --localnet")
+	cmd.Flags().StringVar(&flagType, "type", did.TypeLite.String(), "DID type (basic|standard|wallet|child|lite)")
+	cmd.Flags().StringVar(&flagMnemonicFile, "mnemonic-file", "", "Mnemonic file to recover a lite DID from")
+	cmd.Flags().IntVar(&flagChildPath, "child-path", 0, "Derivation index of a lite DID")
+	cmd.Flags().StringVar(&flagMasterDID, "master-did", "", "Master DID, or its alias, of a child DID")
+	cmd.Flags().StringVar(&flagAlias, "alias", "", "Alias of the DID in the local registry")
+	cmd.Flags().BoolVar(&flagSavePassword, "save-password", false, "Store the password of the DID in the local keystore")
+
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdDIDList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the DIDs created with Rubix Nexus",
+		Long:  "List the DIDs recorded in $HOME/.rubix-nexus/dids.json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			records := registry.List()
+			return printResult(cmd, records, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ALIAS\tDID\tTYPE\tNETWORK\tCREATED")
+				for _, record := range records {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", record.Alias, record.DID, record.Type, record.Network, record.CreatedAt.Format(time.RFC3339))
+				}
+				w.Flush()
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdDIDShow() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show [did|alias]",
+		Short: "Show a DID created with Rubix Nexus",
+		Long:  "Show the record of a DID, looked up by DID or alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			record, err := registry.Find(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printResult(cmd, record, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "DID:\t%s\n", record.DID)
+				fmt.Fprintf(w, "Alias:\t%s\n", record.Alias)
+				fmt.Fprintf(w, "Peer ID:\t%s\n", record.PeerID)
+				fmt.Fprintf(w, "Type:\t%s\n", record.Type)
+				fmt.Fprintf(w, "Network:\t%s\n", record.Network)
+				fmt.Fprintf(w, "Created:\t%s\n", record.CreatedAt.Format(time.RFC3339))
+				w.Flush()
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func didAliasCommands() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "DID alias related sub-commands",
+		Long:  "Manage the aliases of the DIDs created with Rubix Nexus",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		cmdDIDAliasSet(),
+	)
+
+	return cmd
+}
+
+func cmdDIDAliasSet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [did|alias] [alias]",
+		Short: "Set the alias of a DID",
+		Long:  "Set the alias of a DID created with Rubix Nexus. Aliases are accepted by every --*-did flag",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			if err := registry.SetAlias(args[0], args[1]); err != nil {
+				return fmt.Errorf("failed to set alias: %w", err)
+			}
+
+			record, _ := registry.Find(args[1])
+			return printResult(cmd, record, func() {
+				cmd.Printf("Alias '%s' set for %s\n", record.Alias, record.DID)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdDIDBalance() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance [did|alias]",
+		Short: "Show the RBT balance of a DID",
+		Long:  "Show the available, locked, pledged and pinned RBT of a DID, defaulting to default_did of the network",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			didID, err := resolveDID(cfg, firstArg(args), "a DID argument")
+			if err != nil {
+				return err
+			}
 
+			balance, err := did.GetBalance(cmd.Context(), cfg, didID)
+			if err != nil {
+				return fmt.Errorf("failed to get balance: %w", err)
+			}
+
+			return printResult(cmd, balance, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "DID:\t%s\n", balance.DID)
+				fmt.Fprintf(w, "Available:\t%v RBT\n", balance.Available)
+				fmt.Fprintf(w, "Locked:\t%v RBT\n", balance.Locked)
+				fmt.Fprintf(w, "Pledged:\t%v RBT\n", balance.Pledged)
+				fmt.Fprintf(w, "Pinned:\t%v RBT\n", balance.Pinned)
+				w.Flush()
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdDIDTokens() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens [did|alias]",
+		Short: "List the RBT tokens of a DID",
+		Long:  "List the RBT tokens held by a DID and their status, defaulting to default_did of the network",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			didID, err := resolveDID(cfg, firstArg(args), "a DID argument")
+			if err != nil {
+				return err
+			}
+
+			tokens, err := did.ListTokens(cmd.Context(), cfg, didID)
+			if err != nil {
+				return fmt.Errorf("failed to list tokens: %w", err)
+			}
+
+			return printResult(cmd, tokens, func() {
+				w := tabwriter.NewWriter(cmd.OutOrStderr(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "TOKEN\tSTATUS")
+				for _, token := range tokens {
+					fmt.Fprintf(w, "%s\t%s\n", token.Token, token.Status)
+				}
+				w.Flush()
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdDIDFaucet() *cobra.Command {
+	var flagTokens int
+
+	cmd := &cobra.Command{
+		Use:   "faucet [did|alias]",
+		Short: "Generate test RBT tokens for a DID",
+		Long:  "Generate test RBT tokens for a DID on a localnet setup, defaulting to default_did of the network",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flagTokens < 1 {
+				return usageErrorf("--tokens must be at least 1")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			didID, err := resolveDID(cfg, firstArg(args), "a DID argument")
+			if err != nil {
+				return err
+			}
+
+			password, err := resolvePassword(cmd, didID, false)
+			if err != nil {
+				return err
+			}
+
+			printProgress(cmd, "Generating test RBT tokens...")
+			result, err := did.GenerateTestRBT(cmd.Context(), cfg, didID, password, flagTokens)
+			if err != nil {
+				return fmt.Errorf("failed to generate test RBT: %w", err)
+			}
+
+			return printResult(cmd, result, func() {
+				cmd.Printf("%d test RBT tokens generated for %s\n", result.Tokens, result.DID)
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&flagTokens, "tokens", 1, "Number of test RBT tokens to generate")
 	cmd.SilenceUsage = true
 	return cmd
 }
+
+func cmdDIDTransfer() *cobra.Command {
+	var (
+		flagFrom       string
+		flagTo         string
+		flagAmount     float64
+		flagComment    string
+		flagQuorumType int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Transfer RBT between DIDs",
+		Long:  "Transfer RBT from a DID to another, signed with the password of the sender. The sender defaults to default_did of the network",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flagTo == "" {
+				return usageErrorf("--to is required")
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			from, err := resolveDID(cfg, flagFrom, "--from")
+			if err != nil {
+				return err
+			}
+			to, err := resolveDID(cfg, flagTo, "--to")
+			if err != nil {
+				return err
+			}
+
+			request := &did.TransferRequest{
+				From:       from,
+				To:         to,
+				Amount:     flagAmount,
+				Comment:    flagComment,
+				QuorumType: flagQuorumType,
+			}
+			if err := request.Validate(); err != nil {
+				return err
+			}
+
+			password, err := resolvePassword(cmd, from, false)
+			if err != nil {
+				return err
+			}
+
+			printProgress(cmd, "Transferring RBT...")
+			result, err := did.TransferRBT(cmd.Context(), cfg, request, password)
+			if err != nil {
+				return fmt.Errorf("transfer failed: %w", err)
+			}
+
+			return printResult(cmd, result, func() {
+				if result.Warning != "" {
+					cmd.Printf("Transferred %v RBT from %s to %s\n", result.Amount, result.From, result.To)
+					cmd.Printf("Warning: %s\n", result.Warning)
+					return
+				}
+				cmd.Printf("Transferred %v RBT from %s to %s, transaction ID: %s\n", result.Amount, result.From, result.To, result.TransactionID)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&flagFrom, "from", "", "Sender DID or alias (defaults to default_did of the network)")
+	cmd.Flags().StringVar(&flagTo, "to", "", "Receiver DID or alias")
+	cmd.Flags().Float64Var(&flagAmount, "amount", 0, "RBT amount to transfer")
+	cmd.Flags().StringVar(&flagComment, "comment", "", "Comment of the transfer")
+	cmd.Flags().IntVar(&flagQuorumType, "quorum-type", did.QuorumPrivate, "Quorum type validating the transfer (1: public, 2: private)")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// firstArg returns the first argument, or an empty string if there is none
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// resolveDID returns the DID given to a flag or argument, or the default DID
+// of the network profile if it is not set. source names the flag or argument
+// in the error reported when there is no DID, such as "--deployer-did".
+// Aliases are resolved to their DID
+func resolveDID(cfg *config.Config, value string, source string) (string, error) {
+	if value == "" {
+		value = cfg.Network.DefaultDID
+	}
+	if value == "" {
+		return "", usageErrorf("%s is required, or set default_did of network %q", source, cfg.NetworkName)
+	}
+
+	registry, err := did.OpenRegistry(flagHomeDir)
+	if err != nil {
+		return "", err
+	}
+	return registry.Resolve(value), nil
+}
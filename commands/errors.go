@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/contract"
+	"github.com/rubixchain/rubix-nexus/did"
+	"github.com/rubixchain/rubix-nexus/keystore"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+	"github.com/rubixchain/rubix-nexus/scenario"
+)
+
+// Exit codes returned by the CLI for each class of failure
+const (
+	ExitOK              = 0
+	ExitError           = 1
+	ExitUsage           = 2
+	ExitConfig          = 3
+	ExitBuild           = 4
+	ExitNodeUnreachable = 5
+	ExitNodeRejected    = 6
+	ExitSignature       = 7
+	ExitWasm            = 8
+	ExitBalance         = 9
+)
+
+// errUsage is matched by errors caused by invalid command line input
+var errUsage = errors.New("usage error")
+
+// errorClass maps the sentinel errors of a failure class to its exit code
+type errorClass struct {
+	name      string
+	exitCode  int
+	sentinels []error
+}
+
+// errorClasses are matched in order, so classes wrapping node errors
+// (such as signature failures) must come before the node classes
+var errorClasses = []errorClass{
+	{
+		name:      "usage",
+		exitCode:  ExitUsage,
+		sentinels: []error{errUsage, errPasswordRequired, did.ErrInvalidOptions, did.ErrNotRegistered, did.ErrInvalidAlias, contract.ErrInvalidMessage, contract.ErrNoDeployment, contract.ErrSnapshotNotFound, contract.ErrInvalidSnapshot, contract.ErrNoFixtures, scenario.ErrInvalidScenario},
+	},
+	{
+		name:      "config",
+		exitCode:  ExitConfig,
+		sentinels: []error{config.ErrConfigNotFound, config.ErrConfigExists, config.ErrInvalidConfig, config.ErrNetworkNotFound, config.ErrNetworkExists, config.ErrKeyNotFound},
+	},
+	{
+		name:      "build",
+		exitCode:  ExitBuild,
+		sentinels: []error{contract.ErrInvalidContractDir, contract.ErrBuild, contract.ErrArtifactNotFound, contract.ErrStaleArtifact, contract.ErrInvalidArtifact},
+	},
+	{
+		name:      "signature",
+		exitCode:  ExitSignature,
+		sentinels: []error{contract.ErrSignature, did.ErrSignature, keystore.ErrWrongPassphrase, keystore.ErrNotFound},
+	},
+	{
+		name:      "wasm",
+		exitCode:  ExitWasm,
+		sentinels: []error{contract.ErrWasmRuntime, contract.ErrPreflight, contract.ErrDiverged},
+	},
+	{
+		name:      "insufficient_balance",
+		exitCode:  ExitBalance,
+		sentinels: []error{contract.ErrInsufficientBalance},
+	},
+	{
+		name:      "node_unreachable",
+		exitCode:  ExitNodeUnreachable,
+		sentinels: []error{rubixapi.ErrNodeUnreachable},
+	},
+	{
+		name:      "node_rejected",
+		exitCode:  ExitNodeRejected,
+		sentinels: []error{rubixapi.ErrNodeRejected},
+	},
+}
+
+// classifyError returns the failure class name and exit code of err
+func classifyError(err error) (string, int) {
+	for _, class := range errorClasses {
+		for _, sentinel := range class.sentinels {
+			if errors.Is(err, sentinel) {
+				return class.name, class.exitCode
+			}
+		}
+	}
+	return "error", ExitError
+}
+
+// usageError is an error caused by invalid command line input
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+func (e *usageError) Is(target error) bool {
+	return target == errUsage
+}
+
+// usageErrorf returns a usageError with a formatted message
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/contract"
+	"github.com/rubixchain/rubix-nexus/did"
+	"github.com/rubixchain/rubix-nexus/keystore"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+)
+
+func TestClassifyError(t *testing.T) {
+	unreachable := &rubixapi.Error{
+		Endpoint: "/api/execute-smart-contract",
+		Err:      fmt.Errorf("%w: failed to send request: %w", rubixapi.ErrNodeUnreachable, errors.New("connection refused")),
+	}
+	rejected := &rubixapi.Error{Endpoint: "/api/signature-response", StatusCode: 200, Message: "invalid password"}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantName string
+		wantCode int
+	}{
+		{
+			name:     "unclassified",
+			err:      errors.New("something failed"),
+			wantName: "error",
+			wantCode: ExitError,
+		},
+		{
+			name:     "usage",
+			err:      usageErrorf("--contract-dir is required"),
+			wantName: "usage",
+			wantCode: ExitUsage,
+		},
+		{
+			name:     "invalid message",
+			err:      fmt.Errorf("execution failed: %w", fmt.Errorf("%w: %w", contract.ErrInvalidMessage, errors.New("bad JSON"))),
+			wantName: "usage",
+			wantCode: ExitUsage,
+		},
+		{
+			name:     "config",
+			err:      fmt.Errorf("%w: network %q", config.ErrNetworkNotFound, "testnet"),
+			wantName: "config",
+			wantCode: ExitConfig,
+		},
+		{
+			name:     "build",
+			err:      fmt.Errorf("execution failed: %w", fmt.Errorf("%w: src/lib.rs changed", contract.ErrStaleArtifact)),
+			wantName: "build",
+			wantCode: ExitBuild,
+		},
+		{
+			name:     "node unreachable",
+			err:      fmt.Errorf("execution failed: %w", fmt.Errorf("failed to execute smart contract: %w", unreachable)),
+			wantName: "node_unreachable",
+			wantCode: ExitNodeUnreachable,
+		},
+		{
+			name:     "node rejected",
+			err:      fmt.Errorf("deployment failed: %w", fmt.Errorf("failed to generate smart contract: %w", rejected)),
+			wantName: "node_rejected",
+			wantCode: ExitNodeRejected,
+		},
+		{
+			name:     "signature before node rejected",
+			err:      fmt.Errorf("execution failed: %w", fmt.Errorf("%w: %w", contract.ErrSignature, rejected)),
+			wantName: "signature",
+			wantCode: ExitSignature,
+		},
+		{
+			name:     "DID signature before node unreachable",
+			err:      fmt.Errorf("transfer failed: %w", fmt.Errorf("%w: %w", did.ErrSignature, unreachable)),
+			wantName: "signature",
+			wantCode: ExitSignature,
+		},
+		{
+			name:     "wrong keystore passphrase",
+			err:      fmt.Errorf("failed to read password of %s: %w", "bafy", keystore.ErrWrongPassphrase),
+			wantName: "signature",
+			wantCode: ExitSignature,
+		},
+		{
+			name:     "contract error in dry run",
+			err:      fmt.Errorf("dry run failed: %w", fmt.Errorf("%w: %w", contract.ErrWasmRuntime, &contract.WasmError{Message: "overflow"})),
+			wantName: "wasm",
+			wantCode: ExitWasm,
+		},
+		{
+			name:     "failed preflight",
+			err:      fmt.Errorf("execution failed: %w", fmt.Errorf("%w, execution not submitted: %w", contract.ErrPreflight, &contract.WasmError{Message: "overflow"})),
+			wantName: "wasm",
+			wantCode: ExitWasm,
+		},
+		{
+			name:     "failed preflight of a stale artifact is a build failure",
+			err:      fmt.Errorf("%w, execution not submitted: %w", contract.ErrPreflight, contract.ErrStaleArtifact),
+			wantName: "build",
+			wantCode: ExitBuild,
+		},
+		{
+			name:     "insufficient balance",
+			err:      fmt.Errorf("deployment failed: %w", fmt.Errorf("%w: deployer has 0 RBT", contract.ErrInsufficientBalance)),
+			wantName: "insufficient_balance",
+			wantCode: ExitBalance,
+		},
+		{
+			name:     "balance check with node unreachable",
+			err:      fmt.Errorf("deployment failed: %w", fmt.Errorf("failed to check deployer balance: %w", unreachable)),
+			wantName: "node_unreachable",
+			wantCode: ExitNodeUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, code := classifyError(tt.err)
+			if name != tt.wantName || code != tt.wantCode {
+				t.Errorf("classifyError(%v) = %s, %d, want %s, %d", tt.err, name, code, tt.wantName, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestExitCodesAreDistinct(t *testing.T) {
+	codes := map[int]string{ExitOK: "ok", ExitError: "error"}
+	for _, class := range errorClasses {
+		if other, ok := codes[class.exitCode]; ok {
+			t.Errorf("class %s shares exit code %d with %s", class.name, class.exitCode, other)
+		}
+		codes[class.exitCode] = class.name
+	}
+}
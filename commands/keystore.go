@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/rubixchain/rubix-nexus/did"
+	"github.com/rubixchain/rubix-nexus/keystore"
+	"github.com/spf13/cobra"
+)
+
+func keystoreCommands() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keystore",
+		Short: "Keystore related sub-commands",
+		Long:  "Manage the DID passwords kept encrypted in $HOME/.rubix-nexus/keystore.json",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(
+		cmdKeystoreAdd(),
+		cmdKeystoreRemove(),
+		cmdKeystoreList(),
+	)
+
+	return cmd
+}
+
+func cmdKeystoreAdd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [did|alias]",
+		Short: "Store the password of a DID",
+		Long:  "Store the private key password of a DID, read from --password-file, RUBIX_NEXUS_PASSWORD or a prompt, encrypted with the keystore passphrase",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+			didID := registry.Resolve(args[0])
+
+			password, err := resolvePassword(cmd, "", false)
+			if err != nil {
+				return err
+			}
+
+			if err := savePassword(cmd, didID, password); err != nil {
+				return err
+			}
+
+			result := struct {
+				DID string `json:"did"`
+			}{
+				DID: didID,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Password of %s stored in the keystore\n", didID)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdKeystoreRemove() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove [did|alias]",
+		Short: "Remove the password of a DID",
+		Long:  "Remove the password of a DID from the keystore",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+			didID := registry.Resolve(args[0])
+
+			store, err := keystore.Open(flagHomeDir)
+			if err != nil {
+				return err
+			}
+			if err := store.Remove(didID); err != nil {
+				return fmt.Errorf("failed to remove password: %w", err)
+			}
+
+			result := struct {
+				DID string `json:"did"`
+			}{
+				DID: didID,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Password of %s removed from the keystore\n", didID)
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdKeystoreList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the DIDs of the keystore",
+		Long:  "List the DIDs the keystore holds passwords for",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := keystore.Open(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			dids := store.DIDs()
+			return printResult(cmd, dids, func() {
+				for _, did := range dids {
+					cmd.Println(did)
+				}
+			})
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var flagOutput string
+
+// validateOutputFormat checks the value of the --output flag
+func validateOutputFormat() error {
+	if flagOutput != outputText && flagOutput != outputJSON {
+		return usageErrorf("invalid output format %q: must be one of %s, %s", flagOutput, outputText, outputJSON)
+	}
+	return nil
+}
+
+// isJSONOutput reports whether the command output is requested in JSON
+func isJSONOutput() bool {
+	return flagOutput == outputJSON
+}
+
+// printResult writes result as a JSON document to stdout in JSON output mode,
+// otherwise it calls printText to write the human readable output
+func printResult(cmd *cobra.Command, result interface{}, printText func()) error {
+	if !isJSONOutput() {
+		printText()
+		return nil
+	}
+
+	return writeJSON(cmd.OutOrStdout(), result)
+}
+
+// printProgress writes a progress message in text output mode only, so that
+// JSON output stays a single document
+func printProgress(cmd *cobra.Command, message string) {
+	if !isJSONOutput() {
+		cmd.Println(message)
+	}
+}
+
+// errorOutput is the JSON document written to stderr when a command fails
+type errorOutput struct {
+	Error    string `json:"error"`
+	Class    string `json:"class"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// printError writes err to w in the requested output format
+func printError(w io.Writer, err error) {
+	class, exitCode := classifyError(err)
+	if !isJSONOutput() {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	if errWrite := writeJSON(w, &errorOutput{Error: err.Error(), Class: class, ExitCode: exitCode}); errWrite != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return nil
+}
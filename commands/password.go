@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/keystore"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Environment variables holding the DID private key password and the
+// passphrase of the local keystore
+var (
+	passwordEnvVar   = config.EnvPrefix + "PASSWORD"
+	passphraseEnvVar = config.EnvPrefix + "KEYSTORE_PASSPHRASE"
+)
+
+var flagPasswordFile string
+
+// errPasswordRequired is returned when no password source is available and
+// the password cannot be prompted for
+var errPasswordRequired = errors.New("a password is required")
+
+// resolvePassword returns the private key password of the DID, read from
+// --password-file, the RUBIX_NEXUS_PASSWORD environment variable, the local
+// keystore or an interactive prompt, in that order. When newDID is set, the
+// prompted password is asked twice
+func resolvePassword(cmd *cobra.Command, did string, newDID bool) (string, error) {
+	if flagPasswordFile != "" {
+		content, err := os.ReadFile(flagPasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		password := strings.TrimRight(string(content), "\r\n")
+		if password == "" {
+			return "", usageErrorf("password file %s is empty", flagPasswordFile)
+		}
+		return password, nil
+	}
+
+	if password := os.Getenv(passwordEnvVar); password != "" {
+		return password, nil
+	}
+
+	if did != "" {
+		store, err := keystore.Open(flagHomeDir)
+		if err != nil {
+			return "", err
+		}
+		if store.Has(did) {
+			passphrase, err := resolvePassphrase(cmd, false)
+			if err != nil {
+				return "", err
+			}
+			return store.Get(did, passphrase)
+		}
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%w: use --password-file, %s or the keystore", errPasswordRequired, passwordEnvVar)
+	}
+	if did == "" {
+		return promptSecret(cmd, "DID password", newDID)
+	}
+	return promptSecret(cmd, fmt.Sprintf("Password of %s", did), newDID)
+}
+
+// resolvePassphrase returns the keystore passphrase, read from the
+// RUBIX_NEXUS_KEYSTORE_PASSPHRASE environment variable or prompted for.
+// When confirm is set, the prompted passphrase is asked twice
+func resolvePassphrase(cmd *cobra.Command, confirm bool) (string, error) {
+	if passphrase := os.Getenv(passphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("%w: set %s to use the keystore", errPasswordRequired, passphraseEnvVar)
+	}
+	return promptSecret(cmd, "Keystore passphrase", confirm)
+}
+
+// promptSecret reads a secret from the terminal without echoing it
+func promptSecret(cmd *cobra.Command, label string, confirm bool) (string, error) {
+	secret, err := readSecret(cmd, label+": ")
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", usageErrorf("%s must not be empty", strings.ToLower(label))
+	}
+
+	if confirm {
+		confirmation, err := readSecret(cmd, "Confirm "+strings.ToLower(label)+": ")
+		if err != nil {
+			return "", err
+		}
+		if confirmation != secret {
+			return "", usageErrorf("%s confirmation does not match", strings.ToLower(label))
+		}
+	}
+
+	return secret, nil
+}
+
+func readSecret(cmd *cobra.Command, prompt string) (string, error) {
+	fmt.Fprint(cmd.ErrOrStderr(), prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+	}
+	return string(secret), nil
+}
+
+// savePassword stores the password of the DID in the local keystore
+func savePassword(cmd *cobra.Command, did string, password string) error {
+	store, err := keystore.Open(flagHomeDir)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := resolvePassphrase(cmd, len(store.DIDs()) == 0)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Put(did, password, passphrase); err != nil {
+		return fmt.Errorf("failed to save password: %w", err)
+	}
+	return nil
+}
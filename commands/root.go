@@ -1,20 +1,30 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
 
+	"github.com/rubixchain/rubix-nexus/config"
 	"github.com/spf13/cobra"
 )
 
-var flagHomeDir string
+var (
+	flagHomeDir string
+	flagNetwork string
+	flagNodeURL string
+)
 
 var rootCmd = &cobra.Command{
 	Use:                        "rubix-nexus",
 	Short:                      "Rubix Nexus - Smart Contract Deployer and Executor",
 	Long:                       "Rubix Nexus - Smart Contract Deployer and Executor",
 	SuggestionsMinimumDistance: 2,
+	SilenceErrors:              true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat()
+	},
 }
 
 func init() {
@@ -24,7 +34,10 @@ func init() {
 		contractCommands(),
 		configCommands(),
 		didCommands(),
+		keystoreCommands(),
+		cmdRun(),
 	)
+	wrapArgs(rootCmd)
 
 	var errHomeDir error
 	flagHomeDir, errHomeDir = getDefaultHomeDir()
@@ -34,8 +47,46 @@ func init() {
 	}
 
 	rootCmd.PersistentFlags().StringVar(&flagHomeDir, "home", flagHomeDir, "Set the home directory for configuration")
+	rootCmd.PersistentFlags().StringVar(&flagNetwork, "network", "", "Network profile to use (defaults to default_network in config)")
+	rootCmd.PersistentFlags().StringVar(&flagNodeURL, "node-url", "", "Deployer node URL, overriding the one of the network profile")
+	rootCmd.PersistentFlags().StringVar(&flagPasswordFile, "password-file", "", "File holding the DID private key password used to sign requests")
+	rootCmd.PersistentFlags().StringVar(&flagOutput, "output", outputText, "Output format (text|json)")
 
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{message: err.Error()}
+	})
+}
+
+// wrapArgs wraps the argument validation of cmd and its subcommands, so
+// that unexpected arguments are reported as usage errors
+func wrapArgs(cmd *cobra.Command) {
+	if validateArgs := cmd.Args; validateArgs != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validateArgs(cmd, args); err != nil {
+				return &usageError{message: err.Error()}
+			}
+			return nil
+		}
+	}
+	for _, subCmd := range cmd.Commands() {
+		wrapArgs(subCmd)
+	}
+}
+
+// loadConfig resolves the configuration, with the global flags taking
+// precedence over environment variables and the configuration file
+func loadConfig() (*config.Config, error) {
+	overrides := config.Overrides{
+		config.KeyNetwork:         flagNetwork,
+		config.KeyDeployerNodeURL: flagNodeURL,
+	}
+
+	cfg, err := config.LoadConfig(flagHomeDir, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
 }
 
 func getDefaultHomeDir() (string, error) {
@@ -55,9 +106,18 @@ func getDefaultHomeDir() (string, error) {
 	return defaultHomeDir, nil
 }
 
+// Execute runs the root command and exits with the code of the failure class
+// of the returned error, if any
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		// Unknown commands are reported by cobra before any command runs,
+		// on the root command
+		if cmd == rootCmd && !errors.Is(err, errUsage) {
+			err = &usageError{message: err.Error()}
+		}
+		_, exitCode := classifyError(err)
+		printError(os.Stderr, err)
+		os.Exit(exitCode)
 	}
 }
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"sort"
+	"time"
+
+	"github.com/rubixchain/rubix-nexus/did"
+	"github.com/rubixchain/rubix-nexus/scenario"
+	"github.com/spf13/cobra"
+)
+
+func cmdRun() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <scenario.yaml>",
+		Short: "Run a deploy and execute scenario",
+		Long:  "Run a YAML scenario: create its DIDs, then run its deploy, execute and faucet steps in order, capturing variables such as contract hashes and checking the expected results. The run stops at the first failed step",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := scenario.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+
+			registry, err := did.OpenRegistry(flagHomeDir)
+			if err != nil {
+				return err
+			}
+
+			report, runErr := scenario.Run(cmd.Context(), cfg, s, scenario.Options{
+				Registry: registry,
+				Password: func(signerDid string, newDID bool) (string, error) {
+					return resolvePassword(cmd, signerDid, newDID)
+				},
+				OnProgress: func(message string) {
+					printProgress(cmd, message)
+				},
+			})
+
+			if err := printResult(cmd, report, func() {
+				for _, step := range report.Steps {
+					switch {
+					case step.Passed:
+						cmd.Printf("PASS  %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+					case step.Failure != "":
+						cmd.Printf("FAIL  %s (%s): %s\n", step.Name, step.Duration.Round(time.Millisecond), step.Failure)
+					default:
+						cmd.Printf("FAIL  %s (%s): %s\n", step.Name, step.Duration.Round(time.Millisecond), step.Error)
+					}
+					names := make([]string, 0, len(step.Captured))
+					for name := range step.Captured {
+						names = append(names, name)
+					}
+					sort.Strings(names)
+					for _, name := range names {
+						cmd.Printf("      %s = %s\n", name, step.Captured[name])
+					}
+				}
+				if report.Passed {
+					cmd.Printf("Scenario %s passed, %d steps\n", report.Name, len(report.Steps))
+				}
+			}); err != nil {
+				return err
+			}
+
+			return runErr
+		},
+	}
+
+	cmd.SilenceUsage = true
+	return cmd
+}
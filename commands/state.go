@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/rubixchain/rubix-nexus/contract"
+	"github.com/spf13/cobra"
+)
+
+func cmdStateReset() *cobra.Command {
+	var contractDir string
+
+	cmd := &cobra.Command{
+		Use:   "reset",
+		Short: "Reset the local state of a contract project",
+		Long:  "Discard the local state and ledger of a contract project, so that the next local execution starts from the initial state artifacts/state.json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			if err := contract.ResetLocalState(contractDir); err != nil {
+				return err
+			}
+
+			result := struct {
+				ContractDir string `json:"contract_dir"`
+			}{
+				ContractDir: contractDir,
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Local state of %s reset\n", contractDir)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdStateSnapshot() *cobra.Command {
+	var contractDir string
+
+	cmd := &cobra.Command{
+		Use:   "snapshot [name]",
+		Short: "Snapshot the local state of a contract project",
+		Long:  "Save the local state and ledger of a contract project under a name, replacing any snapshot of the same name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			if err := contract.SnapshotLocalState(contractDir, args[0]); err != nil {
+				return fmt.Errorf("failed to snapshot local state: %w", err)
+			}
+
+			result := struct {
+				ContractDir string `json:"contract_dir"`
+				Snapshot    string `json:"snapshot"`
+			}{
+				ContractDir: contractDir,
+				Snapshot:    args[0],
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Local state saved to snapshot '%s'\n", args[0])
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+func cmdStateRestore() *cobra.Command {
+	var contractDir string
+
+	cmd := &cobra.Command{
+		Use:   "restore [name]",
+		Short: "Restore the local state of a contract project",
+		Long:  "Replace the local state and ledger of a contract project by the ones saved by a snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if contractDir == "" {
+				return usageErrorf("--contract-dir is required")
+			}
+
+			if err := contract.RestoreLocalState(contractDir, args[0]); err != nil {
+				return fmt.Errorf("failed to restore local state: %w", err)
+			}
+
+			result := struct {
+				ContractDir string `json:"contract_dir"`
+				Snapshot    string `json:"snapshot"`
+			}{
+				ContractDir: contractDir,
+				Snapshot:    args[0],
+			}
+			return printResult(cmd, result, func() {
+				cmd.Printf("Local state restored from snapshot '%s'\n", args[0])
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&contractDir, "contract-dir", "", "Directory containing the Rust contract project")
+	cmd.SilenceUsage = true
+	return cmd
+}
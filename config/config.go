@@ -6,79 +6,147 @@ import (
 	"path/filepath"
 
 	"github.com/pelletier/go-toml/v2"
+	"github.com/rubixchain/rubix-nexus/utils"
 )
 
-// LoadConfig loads the configuration from the config file
-func LoadConfig(homeDir string) (*Config, error) {
-	configPath := filepath.Join(homeDir, ".rubix-nexus", "config.toml")
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file not found at %s", configPath)
-	}
+const (
+	// LocalnetNetwork is the name of the network profile written by GenerateConfig
+	LocalnetNetwork = "localnet"
 
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+	// legacyNetwork is the name of the profile loaded from the legacy [network] table
+	legacyNetwork = "default"
+)
 
-	var config Config
-	if err := toml.Unmarshal(content, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
+// Dir returns the Rubix Nexus directory under the home directory
+func Dir(homeDir string) string {
+	return filepath.Join(homeDir, ".rubix-nexus")
+}
 
-	return &config, nil
+// FilePath returns the path of the configuration file under the home directory
+func FilePath(homeDir string) string {
+	return filepath.Join(Dir(homeDir), "config.toml")
 }
 
 // GenerateConfig creates a default configuration file
 func GenerateConfig(homeDir string) error {
-	configDir := filepath.Join(homeDir, ".rubix-nexus")
+	configDir := Dir(homeDir)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.toml")
+	configPath := FilePath(homeDir)
 	if _, err := os.Stat(configPath); err == nil {
-		return fmt.Errorf("config file already exists at %s", configPath)
+		return fmt.Errorf("%w at %s", ErrConfigExists, configPath)
 	}
 
-	defaultConfig := Config{
-		Network: NetworkConfig{
-			DeployerNodeURL: "http://localhost:20011",
+	defaultConfig := &Config{
+		DefaultNetwork: LocalnetNetwork,
+		Networks: map[string]NetworkConfig{
+			LocalnetNetwork: {
+				DeployerNodeURL: defaultDeployerNodeURL,
+			},
 		},
 	}
 
-	configContent, err := toml.Marshal(defaultConfig)
+	return writeConfigFile(homeDir, defaultConfig)
+}
+
+// ValidateConfig checks if the configuration file is valid
+func ValidateConfig(homeDir string) error {
+	config, err := ReadConfig(homeDir)
 	if err != nil {
-		return fmt.Errorf("failed to marshal default config: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(configPath, configContent, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	return config.validate()
+}
+
+// validate checks the required fields of the configuration
+func (c *Config) validate() error {
+	if len(c.Networks) == 0 {
+		return fmt.Errorf("%w: at least one network is required", ErrInvalidConfig)
+	}
+	for name, network := range c.Networks {
+		if network.DeployerNodeURL == "" {
+			return fmt.Errorf("%w: deployer_node_url is required for network %q", ErrInvalidConfig, name)
+		}
+	}
+	if err := c.checkHostFunctions(); err != nil {
+		return err
 	}
 
-	return nil
+	return c.checkDefaultNetwork()
 }
 
-// ValidateConfig checks if the configuration file is valid
-func ValidateConfig(homeDir string) error {
-	configPath := filepath.Join(homeDir, ".rubix-nexus", "config.toml")
+// ReadConfig reads the configuration file without resolving the active network
+func ReadConfig(homeDir string) (*Config, error) {
+	configPath := FilePath(homeDir)
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return fmt.Errorf("config file not found at %s", configPath)
+		return nil, fmt.Errorf("%w at %s", ErrConfigNotFound, configPath)
 	}
 
 	content, err := os.ReadFile(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("%w: failed to read config file: %w", ErrInvalidConfig, err)
+	}
+
+	config, err := parseConfig(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse config file: %w", ErrInvalidConfig, err)
 	}
+	return config, nil
+}
 
+// parseConfig decodes the content of the configuration file
+func parseConfig(content []byte) (*Config, error) {
 	var config Config
 	if err := toml.Unmarshal(content, &config); err != nil {
-		return fmt.Errorf("invalid config file format: %w", err)
+		return nil, err
 	}
 
-	// Validate required fields
-	if config.Network.DeployerNodeURL == "" {
-		return fmt.Errorf("deployer_node_url is required")
+	// Load the legacy [network] table as a profile. The first edit of the
+	// file migrates it to [networks.default], see migrateLegacyNetwork
+	if config.LegacyNetwork != nil {
+		if config.Networks == nil {
+			config.Networks = make(map[string]NetworkConfig)
+		}
+		if _, ok := config.Networks[legacyNetwork]; !ok {
+			config.Networks[legacyNetwork] = *config.LegacyNetwork
+		}
+		if config.DefaultNetwork == "" {
+			config.DefaultNetwork = legacyNetwork
+		}
+		config.LegacyNetwork = nil
 	}
 
+	return &config, nil
+}
+
+// writeConfigFile writes the configuration file
+func writeConfigFile(homeDir string, config *Config) error {
+	configContent, err := toml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(FilePath(homeDir), configContent, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// checkDefaultNetwork verifies that the default network, if set, is one of the networks
+func (c *Config) checkDefaultNetwork() error {
+	if c.DefaultNetwork == "" {
+		if len(c.Networks) > 1 {
+			return fmt.Errorf("%w: default_network is required when several networks are configured", ErrInvalidConfig)
+		}
+		return nil
+	}
+
+	if _, ok := c.Networks[c.DefaultNetwork]; !ok {
+		return fmt.Errorf("%w: default_network %q is not one of the networks", ErrInvalidConfig, c.DefaultNetwork)
+	}
 	return nil
 }
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rubixchain/rubix-nexus/utils"
+)
+
+// The configuration file is edited line by line rather than re-encoded, so
+// that comments, formatting and keys unknown to Config are kept intact
+
+var (
+	tableHeaderRegex = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	keyValueRegex    = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+|"[^"]*"|'[^']*')\s*=`)
+)
+
+// editConfigFile applies edit to the content of the configuration file and
+// writes the result, once it is verified to still be a valid configuration
+func editConfigFile(homeDir string, edit func(content []byte) ([]byte, error)) error {
+	configPath := FilePath(homeDir)
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w at %s", ErrConfigNotFound, configPath)
+		}
+		return fmt.Errorf("%w: failed to read config file: %w", ErrInvalidConfig, err)
+	}
+
+	content, err = migrateLegacyNetwork(content)
+	if err != nil {
+		return err
+	}
+
+	editedContent, err := edit(content)
+	if err != nil {
+		return err
+	}
+
+	config, err := parseConfig(editedContent)
+	if err != nil {
+		return fmt.Errorf("%w: unable to edit config file, edit it manually: %w", ErrInvalidConfig, err)
+	}
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	if err := utils.WriteFileAtomic(configPath, editedContent, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// migrateLegacyNetwork turns the legacy [network] table into the "default"
+// network profile, [networks.default], made the default network if there is
+// none. The table is dropped if the profile already exists, as it is ignored
+func migrateLegacyNetwork(content []byte) ([]byte, error) {
+	var config Config
+	if err := toml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse config file: %w", ErrInvalidConfig, err)
+	}
+	if config.LegacyNetwork == nil {
+		return content, nil
+	}
+	if _, ok := config.Networks[legacyNetwork]; ok {
+		return removeTOMLTable(content, []string{"network"})
+	}
+
+	lines := splitLines(content)
+	start, _, found := findTable(lines, "network")
+	if !found {
+		return nil, fmt.Errorf("%w: unable to migrate the [network] table to [networks.%s], edit it manually", ErrInvalidConfig, legacyNetwork)
+	}
+	// Rename the header, keeping its indentation and any trailing comment
+	header := lines[start-1]
+	lines[start-1] = header[:strings.Index(header, "[")] + "[networks." + legacyNetwork + "]" + trailingComment(header[strings.Index(header, "]")+1:])
+	content = joinLines(lines)
+
+	if config.DefaultNetwork != "" {
+		return content, nil
+	}
+	return setTOMLValue(content, nil, "default_network", legacyNetwork)
+}
+
+// setTOMLValue sets key to value in the given table, adding the key or the
+// table if they do not exist yet
+func setTOMLValue(content []byte, table []string, key string, value interface{}) ([]byte, error) {
+	encodedValue, err := encodeTOMLValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := splitLines(content)
+	start, end, found := findTable(lines, strings.Join(table, "."))
+	if !found {
+		return insertTOMLTable(content, lines, table, fmt.Sprintf("%s = %s", key, encodedValue)), nil
+	}
+
+	inString := multilineStringLines(lines)
+	lastKeyLine := start - 1
+	for i := start; i < end; i++ {
+		if inString[i] {
+			continue
+		}
+		match := keyValueRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		lastKeyLine = i
+		if strings.Trim(match[1], `"'`) != key {
+			continue
+		}
+
+		// Replace the value, keeping the indentation and any trailing comment
+		prefix := lines[i][:len(match[0])]
+		comment := trailingComment(lines[i][len(match[0]):])
+		lines[i] = strings.TrimRight(prefix, " ") + " " + encodedValue + comment
+		return joinLines(lines), nil
+	}
+
+	// Insert the key after the last key of the table, or right after its header
+	insertAt := lastKeyLine + 1
+	if lastKeyLine < start {
+		insertAt = start
+	}
+	for insertAt < end && inString[insertAt] {
+		insertAt++
+	}
+	line := fmt.Sprintf("%s = %s", key, encodedValue)
+	lines = append(lines[:insertAt], append([]string{line}, lines[insertAt:]...)...)
+	return joinLines(lines), nil
+}
+
+// insertTOMLTable adds a table holding the given line after the last table
+// sharing its parent, or at the end of the file
+func insertTOMLTable(content []byte, lines []string, table []string, line string) []byte {
+	newTable := []string{"", fmt.Sprintf("[%s]", strings.Join(table, ".")), line}
+
+	parent := strings.Join(table[:len(table)-1], ".")
+	inString := multilineStringLines(lines)
+	insertAt := -1
+	for i, l := range lines {
+		if inString[i] {
+			continue
+		}
+		match := tableHeaderRegex.FindStringSubmatch(l)
+		if match == nil || parent == "" {
+			continue
+		}
+		header := normalizeTableName(match[1])
+		if header == parent || strings.HasPrefix(header, parent+".") {
+			_, end, _ := findTable(lines, header)
+			insertAt = end
+		}
+	}
+
+	if insertAt < 0 {
+		var buf bytes.Buffer
+		buf.Write(bytes.TrimRight(content, "\n"))
+		if buf.Len() == 0 {
+			newTable = newTable[1:]
+		} else {
+			buf.WriteString("\n")
+		}
+		for _, l := range newTable {
+			buf.WriteString(l + "\n")
+		}
+		return buf.Bytes()
+	}
+
+	// Keep blank lines separating the parent from the next table after the new table
+	for insertAt > 0 && strings.TrimSpace(lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	lines = append(lines[:insertAt], append(newTable, lines[insertAt:]...)...)
+	return joinLines(lines)
+}
+
+// removeTOMLTable removes a table, its sub-tables and their keys
+func removeTOMLTable(content []byte, table []string) ([]byte, error) {
+	name := strings.Join(table, ".")
+	lines := splitLines(content)
+
+	inString := multilineStringLines(lines)
+	kept := make([]string, 0, len(lines))
+	removing := false
+	for i, line := range lines {
+		if inString[i] {
+			if !removing {
+				kept = append(kept, line)
+			}
+			continue
+		}
+		if match := tableHeaderRegex.FindStringSubmatch(line); match != nil {
+			header := normalizeTableName(match[1])
+			removing = header == name || strings.HasPrefix(header, name+".")
+		}
+		if !removing {
+			kept = append(kept, line)
+		}
+	}
+
+	return joinLines(kept), nil
+}
+
+// findTable returns the range of lines holding the keys of the table. The
+// top-level table spans the lines before the first table header
+func findTable(lines []string, name string) (int, int, bool) {
+	start, found := 0, name == ""
+	inString := multilineStringLines(lines)
+	for i, line := range lines {
+		if inString[i] {
+			continue
+		}
+		match := tableHeaderRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if found {
+			return start, i, true
+		}
+		if normalizeTableName(match[1]) == name {
+			start, found = i+1, true
+		}
+	}
+	return start, len(lines), found
+}
+
+// multilineStringLines reports the lines starting inside a multi-line
+// string, whose content must not be taken for keys or table headers
+func multilineStringLines(lines []string) []bool {
+	inString := make([]bool, len(lines))
+	delimiter := ""
+	for i, line := range lines {
+		inString[i] = delimiter != ""
+		if delimiter == "" && strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		rest := line
+		for {
+			if delimiter == "" {
+				basic, literal := strings.Index(rest, `"""`), strings.Index(rest, "'''")
+				if basic < 0 && literal < 0 {
+					break
+				}
+				if literal < 0 || (basic >= 0 && basic < literal) {
+					rest, delimiter = rest[basic+3:], `"""`
+				} else {
+					rest, delimiter = rest[literal+3:], "'''"
+				}
+			}
+			end := strings.Index(rest, delimiter)
+			if end < 0 {
+				break
+			}
+			rest, delimiter = rest[end+3:], ""
+		}
+	}
+	return inString
+}
+
+// normalizeTableName removes quotes and whitespace around the dotted segments of a table name
+func normalizeTableName(name string) string {
+	segments := strings.Split(name, ".")
+	for i := range segments {
+		segments[i] = strings.Trim(strings.TrimSpace(segments[i]), `"'`)
+	}
+	return strings.Join(segments, ".")
+}
+
+// trailingComment returns the comment following a value, including the
+// whitespace before it, skipping '#' characters inside strings
+func trailingComment(value string) string {
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && c == '#':
+			j := i
+			for j > 0 && (value[j-1] == ' ' || value[j-1] == '\t') {
+				j--
+			}
+			return value[j:]
+		}
+	}
+	return ""
+}
+
+// encodeTOMLValue encodes a scalar value the way go-toml writes it
+func encodeTOMLValue(value interface{}) (string, error) {
+	encoded, err := toml.Marshal(map[string]interface{}{"v": value})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode value: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimPrefix(string(encoded), "v = ")), nil
+}
+
+func splitLines(content []byte) []string {
+	return strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+}
+
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,195 @@
+package config
+
+import "testing"
+
+func TestSetTOMLValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		table   []string
+		key     string
+		value   interface{}
+		want    string
+	}{
+		{
+			name:    "replace keeping trailing comment",
+			content: "[networks.localnet]\ndeployer_node_url = 'http://localhost:20011' # local node\n",
+			table:   []string{"networks", "localnet"},
+			key:     "deployer_node_url",
+			value:   "http://localhost:20012",
+			want:    "[networks.localnet]\ndeployer_node_url = 'http://localhost:20012' # local node\n",
+		},
+		{
+			name:    "comment character inside string",
+			content: "[networks.localnet]\ndefault_did = 'a#b' # alice\n",
+			table:   []string{"networks", "localnet"},
+			key:     "default_did",
+			value:   "bafy",
+			want:    "[networks.localnet]\ndefault_did = 'bafy' # alice\n",
+		},
+		{
+			name:    "quoted key",
+			content: "[networks.localnet]\n\"default_did\" = 'old'\n",
+			table:   []string{"networks", "localnet"},
+			key:     "default_did",
+			value:   "new",
+			want:    "[networks.localnet]\n\"default_did\" = 'new'\n",
+		},
+		{
+			name:    "quoted table header",
+			content: "[networks.\"localnet\"]\ndeployer_node_url = 'a'\n",
+			table:   []string{"networks", "localnet"},
+			key:     "deployer_node_url",
+			value:   "b",
+			want:    "[networks.\"localnet\"]\ndeployer_node_url = 'b'\n",
+		},
+		{
+			name:    "top-level key",
+			content: "default_network = 'localnet'\n\n[networks.localnet]\ndeployer_node_url = 'a'\n",
+			key:     "default_network",
+			value:   "testnet",
+			want:    "default_network = 'testnet'\n\n[networks.localnet]\ndeployer_node_url = 'a'\n",
+		},
+		{
+			name:    "add key after the last key of the table",
+			content: "[networks.localnet]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+			table:   []string{"networks", "localnet"},
+			key:     "default_did",
+			value:   "bafy",
+			want:    "[networks.localnet]\ndeployer_node_url = 'a'\ndefault_did = 'bafy'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+		},
+		{
+			name:    "insert nested table after its siblings",
+			content: "default_network = 'localnet'\n\n[networks]\n[networks.localnet]\ndeployer_node_url = 'a'\n\n[host_functions.get_price]\nmode = 'mock'\n",
+			table:   []string{"networks", "testnet"},
+			key:     "deployer_node_url",
+			value:   "b",
+			want:    "default_network = 'localnet'\n\n[networks]\n[networks.localnet]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n\n[host_functions.get_price]\nmode = 'mock'\n",
+		},
+		{
+			name:    "insert table at the end of the file",
+			content: "default_network = 'localnet'\n",
+			table:   []string{"host_functions", "get_price"},
+			key:     "mode",
+			value:   "mock",
+			want:    "default_network = 'localnet'\n\n[host_functions.get_price]\nmode = 'mock'\n",
+		},
+		{
+			name:    "insert table in an empty file",
+			content: "",
+			table:   []string{"networks", "localnet"},
+			key:     "deployer_node_url",
+			value:   "a",
+			want:    "[networks.localnet]\ndeployer_node_url = 'a'\n",
+		},
+		{
+			name:    "key inside multi-line string",
+			content: "[networks.localnet]\nnotes = \"\"\"\ndefault_did = 'not a key'\n[not.a.table]\n\"\"\"\n",
+			table:   []string{"networks", "localnet"},
+			key:     "default_did",
+			value:   "bafy",
+			want:    "[networks.localnet]\nnotes = \"\"\"\ndefault_did = 'not a key'\n[not.a.table]\n\"\"\"\ndefault_did = 'bafy'\n",
+		},
+		{
+			name:    "key inside multi-line literal string",
+			content: "[networks.localnet]\nnotes = '''\ndefault_did = 'not a key'\n'''\ndefault_did = 'old'\n",
+			table:   []string{"networks", "localnet"},
+			key:     "default_did",
+			value:   "new",
+			want:    "[networks.localnet]\nnotes = '''\ndefault_did = 'not a key'\n'''\ndefault_did = 'new'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setTOMLValue([]byte(tt.content), tt.table, tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("setTOMLValue() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setTOMLValue() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveTOMLTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		table   []string
+		want    string
+	}{
+		{
+			name:    "table and sub-tables",
+			content: "[networks.localnet]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n\n[networks.testnet.extra]\nkey = 1\n\n[host_functions.get_price]\nmode = 'mock'\n",
+			table:   []string{"networks", "testnet"},
+			want:    "[networks.localnet]\ndeployer_node_url = 'a'\n\n[host_functions.get_price]\nmode = 'mock'\n",
+		},
+		{
+			name:    "table sharing a prefix is kept",
+			content: "[networks.test]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+			table:   []string{"networks", "test"},
+			want:    "[networks.testnet]\ndeployer_node_url = 'b'\n",
+		},
+		{
+			name:    "header inside multi-line string",
+			content: "[networks.localnet]\nnotes = \"\"\"\n[networks.testnet]\n\"\"\"\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+			table:   []string{"networks", "testnet"},
+			want:    "[networks.localnet]\nnotes = \"\"\"\n[networks.testnet]\n\"\"\"\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := removeTOMLTable([]byte(tt.content), tt.table)
+			if err != nil {
+				t.Fatalf("removeTOMLTable() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("removeTOMLTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyNetwork(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "legacy table only",
+			content: "[network] # old layout\ndeployer_node_url = 'http://localhost:20000'\n",
+			want:    "default_network = 'default'\n[networks.default] # old layout\ndeployer_node_url = 'http://localhost:20000'\n",
+		},
+		{
+			name:    "default network kept",
+			content: "default_network = 'testnet'\n\n[network]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+			want:    "default_network = 'testnet'\n\n[networks.default]\ndeployer_node_url = 'a'\n\n[networks.testnet]\ndeployer_node_url = 'b'\n",
+		},
+		{
+			name:    "legacy table shadowed by the default profile",
+			content: "default_network = 'default'\n\n[network]\ndeployer_node_url = 'a'\n\n[networks.default]\ndeployer_node_url = 'b'\n",
+			want:    "default_network = 'default'\n\n[networks.default]\ndeployer_node_url = 'b'\n",
+		},
+		{
+			name:    "no legacy table",
+			content: "default_network = 'localnet'\n\n[networks.localnet]\ndeployer_node_url = 'a'\n",
+			want:    "default_network = 'localnet'\n\n[networks.localnet]\ndeployer_node_url = 'a'\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrateLegacyNetwork([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("migrateLegacyNetwork() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("migrateLegacyNetwork() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
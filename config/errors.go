@@ -0,0 +1,18 @@
+package config
+
+import "errors"
+
+var (
+	// ErrConfigNotFound is returned when the configuration file does not exist
+	ErrConfigNotFound = errors.New("config file not found")
+	// ErrConfigExists is returned when a configuration file is about to be overwritten
+	ErrConfigExists = errors.New("config file already exists")
+	// ErrNetworkNotFound is returned when a network profile does not exist
+	ErrNetworkNotFound = errors.New("network not found")
+	// ErrNetworkExists is returned when a network profile is about to be overwritten
+	ErrNetworkExists = errors.New("network already exists")
+	// ErrKeyNotFound is returned when a configuration key does not exist
+	ErrKeyNotFound = errors.New("config key not found")
+	// ErrInvalidConfig is returned when the configuration file cannot be read or fails validation
+	ErrInvalidConfig = errors.New("invalid configuration")
+)
@@ -0,0 +1,36 @@
+package config
+
+import "fmt"
+
+// Host function modes of local contract executions
+const (
+	// HostFunctionLocal runs the implementation of rubix-nexus, backed by the
+	// local state of the contract project. do_api_call only sends its request
+	// in this mode, and is disabled when no mode is set
+	HostFunctionLocal = "local"
+	// HostFunctionMock returns the configured response or error
+	HostFunctionMock = "mock"
+	// HostFunctionDisabled fails the call
+	HostFunctionDisabled = "disabled"
+)
+
+// validateHostFunctionMode checks that the mode is a host function mode. An
+// empty mode is the local one
+func validateHostFunctionMode(mode string) error {
+	switch mode {
+	case "", HostFunctionLocal, HostFunctionMock, HostFunctionDisabled:
+		return nil
+	default:
+		return fmt.Errorf("host function mode %q must be one of %s, %s or %s", mode, HostFunctionLocal, HostFunctionMock, HostFunctionDisabled)
+	}
+}
+
+// checkHostFunctions verifies the mode of every configured host function
+func (c *Config) checkHostFunctions() error {
+	for name, hostFn := range c.HostFunctions {
+		if err := validateHostFunctionMode(hostFn.Mode); err != nil {
+			return fmt.Errorf("%w: host function %q: %w", ErrInvalidConfig, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// keyValidators validate the values of keys before they are written. A "*"
+// segment matches any network name
+var keyValidators = map[string]func(config *Config, value interface{}) error{
+	"default_network": func(config *Config, value interface{}) error {
+		if _, ok := config.Networks[value.(string)]; !ok {
+			return fmt.Errorf("%w: %q", ErrNetworkNotFound, value)
+		}
+		return nil
+	},
+	"networks.*.deployer_node_url": func(config *Config, value interface{}) error {
+		return validateNodeURL(value.(string))
+	},
+	"host_functions.*.mode": func(config *Config, value interface{}) error {
+		return validateHostFunctionMode(value.(string))
+	},
+}
+
+// GetKey returns the value of a dotted key of the configuration file, such as
+// networks.localnet.deployer_node_url. Keys naming a table return the whole table
+func GetKey(homeDir string, key string) (interface{}, error) {
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := lookupKey(reflect.ValueOf(config).Elem(), splitKey(key), key)
+	if err != nil {
+		return nil, err
+	}
+	if !value.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+	}
+
+	return value.Interface(), nil
+}
+
+// SetKey validates value against the type of a dotted key of the configuration
+// file and writes it. Comments and unknown keys of the file are preserved
+func SetKey(homeDir string, key string, value string) error {
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return err
+	}
+
+	segments := splitKey(key)
+	kind, pattern, err := keyKind(reflect.TypeOf(config).Elem(), segments, key)
+	if err != nil {
+		return err
+	}
+
+	typedValue, err := parseValue(kind, value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid value for %s: %w", ErrInvalidConfig, key, err)
+	}
+
+	if validate, ok := keyValidators[pattern]; ok {
+		if err := validate(config, typedValue); err != nil {
+			return fmt.Errorf("%w: invalid value for %s: %w", ErrInvalidConfig, key, err)
+		}
+	}
+
+	return editConfigFile(homeDir, func(content []byte) ([]byte, error) {
+		return setTOMLValue(content, segments[:len(segments)-1], segments[len(segments)-1], typedValue)
+	})
+}
+
+// splitKey splits a dotted key into its segments
+func splitKey(key string) []string {
+	segments := strings.Split(key, ".")
+	for i := range segments {
+		segments[i] = strings.TrimSpace(segments[i])
+	}
+	return segments
+}
+
+// lookupKey walks the configuration value along the key segments. An invalid
+// value is returned if a map entry on the way does not exist
+func lookupKey(value reflect.Value, segments []string, key string) (reflect.Value, error) {
+	for _, segment := range segments {
+		if segment == "" {
+			return reflect.Value{}, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(value.Type(), segment)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+			}
+			value = value.FieldByIndex(field.Index)
+		case reflect.Map:
+			value = value.MapIndex(reflect.ValueOf(segment))
+			if !value.IsValid() {
+				return reflect.Value{}, nil
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+		}
+	}
+
+	return value, nil
+}
+
+// keyKind returns the kind of the value stored at the key segments and the
+// key pattern of its validator, where map keys are replaced by "*". Only
+// scalar keys can be set
+func keyKind(t reflect.Type, segments []string, key string) (reflect.Kind, string, error) {
+	pattern := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			return reflect.Invalid, "", fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByTag(t, segment)
+			if !ok {
+				return reflect.Invalid, "", fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+			}
+			t = field.Type
+			pattern = append(pattern, segment)
+		case reflect.Map:
+			if !isValidNetworkName(segment) {
+				return reflect.Invalid, "", fmt.Errorf("%w: invalid table name %q", ErrInvalidConfig, segment)
+			}
+			t = t.Elem()
+			pattern = append(pattern, "*")
+		default:
+			return reflect.Invalid, "", fmt.Errorf("%w: %q", ErrKeyNotFound, key)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Float64:
+		return t.Kind(), strings.Join(pattern, "."), nil
+	default:
+		return reflect.Invalid, "", fmt.Errorf("%w: %q is a table, set one of its keys instead", ErrInvalidConfig, key)
+	}
+}
+
+// fieldByTag returns the struct field with the given TOML key. Fields that are
+// not read from the file, including the legacy [network] table, are skipped
+func fieldByTag(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if tag == "" || tag == "-" || field.Type.Kind() == reflect.Pointer {
+			continue
+		}
+		if tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// parseValue converts the command line value to the kind of the key
+func parseValue(kind reflect.Kind, value string) (interface{}, error) {
+	switch kind {
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int:
+		return strconv.Atoi(value)
+	case reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+// validateNodeURL checks that the value is an absolute HTTP(S) URL
+func validateNodeURL(value string) error {
+	nodeURL, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if nodeURL.Scheme != "http" && nodeURL.Scheme != "https" {
+		return fmt.Errorf("node URL %q must use the http or https scheme", value)
+	}
+	if nodeURL.Host == "" {
+		return fmt.Errorf("node URL %q has no host", value)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestSetKeyValidatesEditedConfig(t *testing.T) {
+	homeDir := t.TempDir()
+	if err := GenerateConfig(homeDir); err != nil {
+		t.Fatalf("GenerateConfig() error = %v", err)
+	}
+	original, err := os.ReadFile(FilePath(homeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key   string
+		value string
+	}{
+		// The new network would have no deployer node
+		{key: "networks.newnet.default_did", value: "bafy"},
+		{key: "networks.localnet.deployer_node_url", value: "localhost:20000"},
+		{key: "default_network", value: "testnet"},
+		{key: "host_functions.do_api_call.mode", value: "remote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if err := SetKey(homeDir, tt.key, tt.value); !errors.Is(err, ErrInvalidConfig) && !errors.Is(err, ErrNetworkNotFound) {
+				t.Errorf("SetKey() error = %v, want an invalid configuration", err)
+			}
+			if content, _ := os.ReadFile(FilePath(homeDir)); string(content) != string(original) {
+				t.Errorf("config file changed to\n%s", content)
+			}
+		})
+	}
+
+	if err := SetKey(homeDir, "networks.localnet.default_did", "bafy"); err != nil {
+		t.Fatalf("SetKey() error = %v", err)
+	}
+	if value, err := GetKey(homeDir, "networks.localnet.default_did"); err != nil || value != "bafy" {
+		t.Errorf("GetKey() = %v, %v", value, err)
+	}
+	if entries, _ := os.ReadDir(Dir(homeDir)); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
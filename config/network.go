@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NetworkProfile is a named network profile
+type NetworkProfile struct {
+	Name    string `json:"name"`
+	Default bool   `json:"default"`
+	NetworkConfig
+}
+
+// ListNetworks returns the network profiles of the configuration, sorted by name
+func ListNetworks(homeDir string) ([]*NetworkProfile, error) {
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*NetworkProfile, 0, len(config.Networks))
+	for name, network := range config.Networks {
+		profiles = append(profiles, &NetworkProfile{
+			Name:          name,
+			Default:       name == config.DefaultNetwork,
+			NetworkConfig: network,
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// AddNetwork adds a network profile to the configuration. The first
+// network added becomes the default network
+func AddNetwork(homeDir string, name string, network NetworkConfig) error {
+	if !isValidNetworkName(name) {
+		return fmt.Errorf("%w: invalid network name %q: must contain only lowercase alphanumeric characters, hyphens and underscores", ErrInvalidConfig, name)
+	}
+	if network.DeployerNodeURL == "" {
+		return fmt.Errorf("%w: deployer_node_url is required", ErrInvalidConfig)
+	}
+	if err := validateNodeURL(network.DeployerNodeURL); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := config.Networks[name]; ok {
+		return fmt.Errorf("%w: %q", ErrNetworkExists, name)
+	}
+
+	return editConfigFile(homeDir, func(content []byte) ([]byte, error) {
+		content, err := setTOMLValue(content, []string{"networks", name}, "deployer_node_url", network.DeployerNodeURL)
+		if err != nil || config.DefaultNetwork != "" {
+			return content, err
+		}
+		return setTOMLValue(content, nil, "default_network", name)
+	})
+}
+
+// RemoveNetwork removes a network profile from the configuration. The
+// default network cannot be removed
+func RemoveNetwork(homeDir string, name string) error {
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := config.Networks[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrNetworkNotFound, name)
+	}
+	if name == config.DefaultNetwork {
+		return fmt.Errorf("%w: network %q is the default network, select another default network before removing it", ErrInvalidConfig, name)
+	}
+
+	return editConfigFile(homeDir, func(content []byte) ([]byte, error) {
+		return removeTOMLTable(content, []string{"networks", name})
+	})
+}
+
+// UseNetwork sets the default network of the configuration
+func UseNetwork(homeDir string, name string) error {
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := config.Networks[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrNetworkNotFound, name)
+	}
+
+	return editConfigFile(homeDir, func(content []byte) ([]byte, error) {
+		return setTOMLValue(content, nil, "default_network", name)
+	})
+}
+
+// isValidNetworkName checks if the name can be used as a TOML bare key
+func isValidNetworkName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+// writeLegacyConfig writes a configuration file with the legacy [network] table
+func writeLegacyConfig(t *testing.T) string {
+	t.Helper()
+
+	homeDir := t.TempDir()
+	if err := os.MkdirAll(Dir(homeDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "[network]\ndeployer_node_url = 'http://localhost:20000'\n"
+	if err := os.WriteFile(FilePath(homeDir), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return homeDir
+}
+
+func TestAddNetworkMigratesLegacyNetwork(t *testing.T) {
+	homeDir := writeLegacyConfig(t)
+
+	if err := AddNetwork(homeDir, "testnet", NetworkConfig{DeployerNodeURL: "http://localhost:20001"}); err != nil {
+		t.Fatalf("AddNetwork() error = %v", err)
+	}
+
+	content, err := os.ReadFile(FilePath(homeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "default_network = 'default'\n[networks.default]\ndeployer_node_url = 'http://localhost:20000'\n\n[networks.testnet]\ndeployer_node_url = 'http://localhost:20001'\n"
+	if string(content) != want {
+		t.Errorf("config file =\n%s\nwant\n%s", content, want)
+	}
+	if err := ValidateConfig(homeDir); err != nil {
+		t.Errorf("ValidateConfig() error = %v", err)
+	}
+}
+
+func TestRemoveLegacyNetwork(t *testing.T) {
+	homeDir := writeLegacyConfig(t)
+
+	if err := AddNetwork(homeDir, "testnet", NetworkConfig{DeployerNodeURL: "http://localhost:20001"}); err != nil {
+		t.Fatalf("AddNetwork() error = %v", err)
+	}
+	if err := UseNetwork(homeDir, "testnet"); err != nil {
+		t.Fatalf("UseNetwork() error = %v", err)
+	}
+	if err := RemoveNetwork(homeDir, legacyNetwork); err != nil {
+		t.Fatalf("RemoveNetwork() error = %v", err)
+	}
+
+	profiles, err := ListNetworks(homeDir)
+	if err != nil {
+		t.Fatalf("ListNetworks() error = %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "testnet" {
+		t.Errorf("networks after removing the legacy one = %+v", profiles)
+	}
+}
+
+func TestUseNetworkMigratesLegacyNetwork(t *testing.T) {
+	homeDir := writeLegacyConfig(t)
+
+	// The legacy table is migrated by any edit of the file
+	if err := UseNetwork(homeDir, legacyNetwork); err != nil {
+		t.Fatalf("UseNetwork() error = %v", err)
+	}
+	config, err := ReadConfig(homeDir)
+	if err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+	if config.Networks[legacyNetwork].DeployerNodeURL != "http://localhost:20000" || config.DefaultNetwork != legacyNetwork {
+		t.Errorf("config = %+v", config)
+	}
+	if content, _ := os.ReadFile(FilePath(homeDir)); string(content) != "default_network = 'default'\n[networks.default]\ndeployer_node_url = 'http://localhost:20000'\n" {
+		t.Errorf("config file =\n%s", content)
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+)
+
+// CheckStatus is the outcome of a validation check
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// Check is the result of a single validation check
+type Check struct {
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+	Detail string      `json:"detail"`
+	Err    error       `json:"-"`
+}
+
+// probeToken is a token no contract is deployed as. Fetching its token chain
+// checks that the node serves the smart contract API without changing or
+// reading any node state
+const probeToken = "QmRubixNexusConfigValidateProbe"
+
+// routeProbedEndpoints are the endpoints used by contract deploy and
+// execute, whose routes are checked without calling them
+var routeProbedEndpoints = []string{
+	rubixapi.EndpointGenerateSmartContract,
+	rubixapi.EndpointDeploySmartContract,
+	rubixapi.EndpointExecuteSmartContract,
+	rubixapi.EndpointSignatureResponse,
+}
+
+// CheckNode contacts the deployer node of the resolved configuration and
+// verifies that it serves the smart contract API, calling read-only
+// endpoints only and probing the routes of the others. Once the node is
+// found unreachable, the remaining checks are skipped
+func CheckNode(ctx context.Context, cfg *Config) []*Check {
+	checks := []*Check{}
+
+	urlCheck := &Check{Name: "node url", Status: CheckPass, Detail: cfg.Network.DeployerNodeURL}
+	if err := validateNodeURL(cfg.Network.DeployerNodeURL); err != nil {
+		urlCheck.Status, urlCheck.Detail, urlCheck.Err = CheckFail, err.Error(), fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	checks = append(checks, urlCheck)
+
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
+
+	var status *rubixapi.NodeStatusResponse
+	statusCheck := runCheck(ctx, "node status", urlCheck.Status == CheckPass, func(ctx context.Context) (string, error) {
+		var err error
+		status, err = client.NodeStatus(ctx)
+		if err != nil {
+			return "", err
+		}
+		return status.Message, nil
+	})
+	checks = append(checks, statusCheck)
+	reachable := statusCheck.Status == CheckPass
+
+	checks = append(checks, runCheck(ctx, "node version", reachable, func(ctx context.Context) (string, error) {
+		if status.Version == "" {
+			return "not reported by the node", nil
+		}
+		return status.Version, nil
+	}))
+
+	checks = append(checks, runCheck(ctx, "peer id", reachable, func(ctx context.Context) (string, error) {
+		return client.GetPeerID(ctx)
+	}))
+
+	checks = append(checks, runCheck(ctx, "endpoint "+rubixapi.EndpointSmartContractTokenChainData, reachable, func(ctx context.Context) (string, error) {
+		// The node rejects the unknown token with its API response envelope
+		_, err := client.GetSmartContractTokenChainData(ctx, probeToken, true)
+		var apiErr *rubixapi.Error
+		if err != nil && !(errors.As(err, &apiErr) && apiErr.Err == nil && apiErr.StatusCode == http.StatusOK) {
+			return "", err
+		}
+		return "available", nil
+	}))
+
+	// The endpoints deploying and executing contracts change the node state
+	// when called, only their routes are checked
+	for _, endpoint := range routeProbedEndpoints {
+		checks = append(checks, runCheck(ctx, "route "+endpoint, reachable, func(ctx context.Context) (string, error) {
+			statusCode, err := client.ProbeRoute(ctx, endpoint)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("served (HTTP %d to a probe), not called", statusCode), nil
+		}))
+	}
+
+	return checks
+}
+
+// runCheck runs a check, or skips it if enabled is not set
+func runCheck(ctx context.Context, name string, enabled bool, check func(ctx context.Context) (string, error)) *Check {
+	if !enabled {
+		return &Check{Name: name, Status: CheckSkip}
+	}
+
+	detail, err := check(ctx)
+	if err != nil {
+		return &Check{Name: name, Status: CheckFail, Detail: err.Error(), Err: err}
+	}
+	return &Check{Name: name, Status: CheckPass, Detail: detail}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckNode(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		switch r.URL.Path {
+		case "/api/node-status":
+			w.Write([]byte(`{"status": true, "message": "Node is up and running"}`))
+		case "/api/get-peer-id":
+			w.Write([]byte(`{"status": true, "result": "12D3peer"}`))
+		case "/api/get-smart-contract-token-chain-data":
+			w.Write([]byte(`{"status": false, "message": "token not found"}`))
+		case "/api/generate-smart-contract", "/api/execute-smart-contract", "/api/signature-response":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &Config{Network: NetworkConfig{DeployerNodeURL: server.URL}}
+	checks := CheckNode(context.Background(), cfg)
+
+	want := map[string]CheckStatus{
+		"node url":    CheckPass,
+		"node status": CheckPass,
+		"peer id":     CheckPass,
+		"endpoint /api/get-smart-contract-token-chain-data": CheckPass,
+		"route /api/generate-smart-contract":                CheckPass,
+		"route /api/deploy-smart-contract":                  CheckFail,
+		"route /api/execute-smart-contract":                 CheckPass,
+		"route /api/signature-response":                     CheckPass,
+	}
+	for _, check := range checks {
+		if status, ok := want[check.Name]; ok && check.Status != status {
+			t.Errorf("check %s = %s (%s), want %s", check.Name, check.Status, check.Detail, status)
+		}
+		delete(want, check.Name)
+	}
+	for name := range want {
+		t.Errorf("check %s missing", name)
+	}
+
+	// The endpoints changing the node state are never called
+	for _, call := range calls {
+		if call[:4] == "POST" && call != "POST /api/get-smart-contract-token-chain-data "+`{"latest":true,"token":"`+probeToken+`"}` {
+			t.Errorf("node called with %s", call)
+		}
+	}
+}
+
+func TestCheckNodeUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	checks := CheckNode(context.Background(), &Config{Network: NetworkConfig{DeployerNodeURL: server.URL}})
+	for _, check := range checks[2:] {
+		if check.Status != CheckSkip {
+			t.Errorf("check %s = %s after the node status failed, want skip", check.Name, check.Status)
+		}
+	}
+	if checks[1].Status != CheckFail {
+		t.Errorf("node status = %s, want fail", checks[1].Status)
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source identifies the configuration layer an effective value comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// EnvPrefix is the prefix of the environment variables overriding configuration values
+const EnvPrefix = "RUBIX_NEXUS_"
+
+// Effective configuration keys that can be overridden
+const (
+	KeyNetwork         = "network"
+	KeyDeployerNodeURL = "network.deployer_node_url"
+	KeyDefaultDID      = "network.default_did"
+)
+
+// defaultDeployerNodeURL is the deployer node URL of the localnet network
+const defaultDeployerNodeURL = "http://localhost:20011"
+
+// Overrides holds configuration values set on the command line, keyed by
+// effective configuration key
+type Overrides map[string]string
+
+// ResolvedValue is an effective configuration value and the layer it comes from
+type ResolvedValue struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+	// Origin is the file, environment variable or flag the value was read from
+	Origin string `json:"origin,omitempty"`
+}
+
+// EnvVar returns the name of the environment variable overriding an effective key
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// resolver layers defaults, the configuration file, environment variables
+// and command line flags, in increasing order of precedence
+type resolver struct {
+	configPath string
+	overrides  Overrides
+	resolved   []*ResolvedValue
+}
+
+// resolve returns the effective value of key. fileValue is used when ok is set
+func (r *resolver) resolve(key string, defaultValue string, fileValue string, ok bool) string {
+	value := &ResolvedValue{Key: key, Value: defaultValue, Source: SourceDefault}
+	if ok {
+		value = &ResolvedValue{Key: key, Value: fileValue, Source: SourceFile, Origin: r.configPath}
+	}
+	if envValue := os.Getenv(EnvVar(key)); envValue != "" {
+		value = &ResolvedValue{Key: key, Value: envValue, Source: SourceEnv, Origin: EnvVar(key)}
+	}
+	if flagValue, ok := r.overrides[key]; ok && flagValue != "" {
+		value = &ResolvedValue{Key: key, Value: flagValue, Source: SourceFlag, Origin: flagName(key)}
+	}
+
+	r.resolved = append(r.resolved, value)
+	return value.Value
+}
+
+// flagName returns the command line flag overriding an effective key
+func flagName(key string) string {
+	switch key {
+	case KeyNetwork:
+		return "--network"
+	case KeyDeployerNodeURL:
+		return "--node-url"
+	default:
+		return ""
+	}
+}
+
+// LoadConfig loads the configuration file, if present, and resolves the
+// active network profile by layering defaults, the file, RUBIX_NEXUS_*
+// environment variables and the command line overrides
+func LoadConfig(homeDir string, overrides Overrides) (*Config, error) {
+	config, err := ReadConfig(homeDir)
+	fileFound := err == nil
+	if errors.Is(err, ErrConfigNotFound) {
+		config = &Config{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := config.checkHostFunctions(); err != nil {
+		return nil, err
+	}
+
+	r := &resolver{configPath: FilePath(homeDir), overrides: overrides}
+
+	fileNetwork := config.DefaultNetwork
+	if fileNetwork == "" && len(config.Networks) == 1 {
+		for name := range config.Networks {
+			fileNetwork = name
+		}
+	}
+	networkName := r.resolve(KeyNetwork, LocalnetNetwork, fileNetwork, fileFound && fileNetwork != "")
+
+	network, networkFound := config.Networks[networkName]
+	defaultNodeURL := ""
+	if networkName == LocalnetNetwork {
+		defaultNodeURL = defaultDeployerNodeURL
+	}
+	network.DeployerNodeURL = r.resolve(KeyDeployerNodeURL, defaultNodeURL, network.DeployerNodeURL, networkFound && network.DeployerNodeURL != "")
+
+	if network.DeployerNodeURL == "" {
+		if !networkFound {
+			return nil, fmt.Errorf("%w: %q", ErrNetworkNotFound, networkName)
+		}
+		return nil, fmt.Errorf("%w: deployer_node_url is required for network %q", ErrInvalidConfig, networkName)
+	}
+	if err := validateNodeURL(network.DeployerNodeURL); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	network.DefaultDID = r.resolve(KeyDefaultDID, "", network.DefaultDID, networkFound && network.DefaultDID != "")
+
+	config.NetworkName = networkName
+	config.Network = network
+	config.Resolved = r.resolved
+	return config, nil
+}
@@ -1,10 +1,38 @@
 package config
 
 type Config struct {
-	Network NetworkConfig `toml:"network"`
+	DefaultNetwork string                   `toml:"default_network" json:"default_network"`
+	Networks       map[string]NetworkConfig `toml:"networks" json:"networks"`
+
+	// HostFunctions configures the host functions of local contract executions, by host function name
+	HostFunctions map[string]HostFunctionConfig `toml:"host_functions,omitempty" json:"host_functions,omitempty"`
+
+	// LegacyNetwork is the single [network] table of configs written before
+	// network profiles were introduced. It is loaded as the "default" profile
+	LegacyNetwork *NetworkConfig `toml:"network,omitempty" json:"-"`
+
+	// NetworkName and Network hold the active network profile resolved by LoadConfig
+	NetworkName string        `toml:"-" json:"-"`
+	Network     NetworkConfig `toml:"-" json:"-"`
+
+	// Resolved lists the effective values resolved by LoadConfig and their sources
+	Resolved []*ResolvedValue `toml:"-" json:"-"`
 }
 
 type NetworkConfig struct {
-	DeployerNodeURL string `toml:"deployer_node_url"`
+	DeployerNodeURL string `toml:"deployer_node_url" json:"deployer_node_url"`
+	// DefaultDID is the DID, or DID alias, used when a command is not given one
+	DefaultDID string `toml:"default_did,omitempty" json:"default_did,omitempty"`
 }
 
+// HostFunctionConfig selects how a host function behaves when a contract is
+// executed locally
+type HostFunctionConfig struct {
+	// Mode is one of local, mock or disabled. Host functions default to
+	// local, except do_api_call which defaults to disabled
+	Mode string `toml:"mode" json:"mode"`
+	// Response is the response of a mocked host function
+	Response string `toml:"response,omitempty" json:"response,omitempty"`
+	// Error makes a mocked host function fail with this message
+	Error string `toml:"error,omitempty" json:"error,omitempty"`
+}
@@ -0,0 +1,193 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// artifactsDir is the directory of a contract project holding its build artifacts
+	artifactsDir = "artifacts"
+	// artifactManifestFile describes the WASM artifact of a contract project
+	artifactManifestFile = "manifest.json"
+)
+
+// Artifact describes the WASM binary built from a contract project
+type Artifact struct {
+	Crate     string    `json:"crate"`
+	WasmFile  string    `json:"wasm_file"`
+	Profile   string    `json:"profile"`
+	Features  []string  `json:"features,omitempty"`
+	Optimized bool      `json:"optimized"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	BuiltAt   time.Time `json:"built_at"`
+
+	// Path is the path of the WASM binary
+	Path string `json:"-"`
+}
+
+// ArtifactsDir returns the artifacts directory of a contract project
+func ArtifactsDir(contractDir string) string {
+	return filepath.Join(contractDir, artifactsDir)
+}
+
+// crateName returns the name of the library crate of a contract project, as
+// used for the name of its WASM binary
+func crateName(contractDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(contractDir, "Cargo.toml"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read Cargo.toml: %w", err)
+	}
+
+	var manifest struct {
+		Package struct {
+			Name string `toml:"name"`
+		} `toml:"package"`
+		Lib struct {
+			Name string `toml:"name"`
+		} `toml:"lib"`
+	}
+	if err := toml.Unmarshal(content, &manifest); err != nil {
+		return "", fmt.Errorf("failed to parse Cargo.toml: %w", err)
+	}
+
+	name := manifest.Lib.Name
+	if name == "" {
+		name = manifest.Package.Name
+	}
+	if name == "" {
+		return "", fmt.Errorf("Cargo.toml has no package name")
+	}
+
+	return strings.ReplaceAll(name, "-", "_"), nil
+}
+
+// writeArtifact copies the WASM binary built by cargo to the artifacts
+// directory of the contract project, completes the artifact description and
+// writes it to the manifest
+func writeArtifact(contractDir string, artifact *Artifact, builtWasm string) error {
+	if err := os.MkdirAll(ArtifactsDir(contractDir), 0755); err != nil {
+		return fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	input, err := os.ReadFile(builtWasm)
+	if err != nil {
+		return fmt.Errorf("failed to read WASM file: %w", err)
+	}
+
+	artifact.WasmFile = artifact.Crate + ".wasm"
+	artifact.Size = int64(len(input))
+	artifact.BuiltAt = time.Now().UTC()
+	artifact.Path = filepath.Join(ArtifactsDir(contractDir), artifact.WasmFile)
+
+	if err := os.WriteFile(artifact.Path, input, 0644); err != nil {
+		return fmt.Errorf("failed to copy WASM file to artifacts: %w", err)
+	}
+	if artifact.SHA256, err = fileSHA256(artifact.Path); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode artifact manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(ArtifactsDir(contractDir), artifactManifestFile), content, 0644); err != nil {
+		return fmt.Errorf("failed to write artifact manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveArtifact returns the WASM artifact of a contract project, named
+// after its crate. An error is returned if the artifact does not exist, was
+// modified after it was built or is older than the sources of the project
+func ResolveArtifact(contractDir string) (*Artifact, error) {
+	crate, err := crateName(contractDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidContractDir, err)
+	}
+
+	artifact := &Artifact{Crate: crate, WasmFile: crate + ".wasm"}
+	content, err := os.ReadFile(filepath.Join(ArtifactsDir(contractDir), artifactManifestFile))
+	if err == nil {
+		if err := json.Unmarshal(content, artifact); err != nil {
+			return nil, fmt.Errorf("failed to parse artifact manifest: %w", err)
+		}
+		if artifact.Crate != crate {
+			return nil, fmt.Errorf("%w: artifact was built for crate %s, not %s", ErrStaleArtifact, artifact.Crate, crate)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read artifact manifest: %w", err)
+	}
+	artifact.Path = filepath.Join(ArtifactsDir(contractDir), artifact.WasmFile)
+
+	wasmInfo, err := os.Stat(artifact.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s, build the contract first", ErrArtifactNotFound, artifact.Path)
+	}
+
+	if artifact.SHA256 != "" {
+		sha, err := fileSHA256(artifact.Path)
+		if err != nil {
+			return nil, err
+		}
+		if sha != artifact.SHA256 {
+			return nil, fmt.Errorf("%w: %s was modified after it was built", ErrStaleArtifact, artifact.Path)
+		}
+	} else {
+		artifact.Size = wasmInfo.Size()
+	}
+
+	sourceFile, sourceModTime, err := latestSourceChange(contractDir)
+	if err != nil {
+		return nil, err
+	}
+	if sourceModTime.After(wasmInfo.ModTime()) {
+		return nil, fmt.Errorf("%w: %s changed after %s was built, rebuild the contract", ErrStaleArtifact, sourceFile, artifact.WasmFile)
+	}
+
+	return artifact, nil
+}
+
+// latestSourceChange returns the most recently modified file among the
+// sources and the Cargo.toml of a contract project
+func latestSourceChange(contractDir string) (string, time.Time, error) {
+	var (
+		latestFile string
+		latestTime time.Time
+	)
+
+	cargoInfo, err := os.Stat(filepath.Join(contractDir, "Cargo.toml"))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read Cargo.toml: %w", err)
+	}
+	latestFile, latestTime = "Cargo.toml", cargoInfo.ModTime()
+
+	err = filepath.WalkDir(filepath.Join(contractDir, "src"), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latestTime) {
+			latestFile, _ = filepath.Rel(contractDir, path)
+			latestTime = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read contract sources: %w", err)
+	}
+
+	return latestFile, latestTime, nil
+}
@@ -0,0 +1,202 @@
+package contract
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestProject creates a contract project whose sources were last
+// modified an hour ago
+func newTestProject(t *testing.T, cargoToml string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "Cargo.toml"), cargoToml)
+	writeTestFile(t, filepath.Join(dir, "src", "lib.rs"), "// contract\n")
+
+	past := time.Now().Add(-time.Hour)
+	for _, file := range []string{"Cargo.toml", filepath.Join("src", "lib.rs")} {
+		if err := os.Chtimes(filepath.Join(dir, file), past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// buildTestArtifact writes a WASM artifact and its manifest, as a build does
+func buildTestArtifact(t *testing.T, dir string, crate string) *Artifact {
+	t.Helper()
+
+	builtWasm := filepath.Join(t.TempDir(), crate+".wasm")
+	writeTestFile(t, builtWasm, "\x00asm\x01\x00\x00\x00")
+	artifact := &Artifact{Crate: crate, Profile: "debug"}
+	if err := writeArtifact(dir, artifact, builtWasm); err != nil {
+		t.Fatalf("writeArtifact() error = %v", err)
+	}
+	return artifact
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrateName(t *testing.T) {
+	tests := []struct {
+		name      string
+		cargoToml string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "package name with hyphens",
+			cargoToml: "[package]\nname = \"my-contract\"\n",
+			want:      "my_contract",
+		},
+		{
+			name:      "lib name takes precedence",
+			cargoToml: "[package]\nname = \"my-contract\"\n\n[lib]\nname = \"custom-lib\"\ncrate-type = [\"cdylib\"]\n",
+			want:      "custom_lib",
+		},
+		{
+			name:      "lib without name",
+			cargoToml: "[package]\nname = \"counter\"\n\n[lib]\ncrate-type = [\"cdylib\"]\n",
+			want:      "counter",
+		},
+		{
+			name:      "no package name",
+			cargoToml: "[dependencies]\n",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestProject(t, tt.cargoToml)
+			got, err := crateName(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("crateName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("crateName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveArtifact(t *testing.T) {
+	dir := newTestProject(t, "[package]\nname = \"my-contract\"\n")
+	built := buildTestArtifact(t, dir, "my_contract")
+
+	artifact, err := ResolveArtifact(dir)
+	if err != nil {
+		t.Fatalf("ResolveArtifact() error = %v", err)
+	}
+	if artifact.Path != filepath.Join(dir, "artifacts", "my_contract.wasm") {
+		t.Errorf("Path = %s", artifact.Path)
+	}
+	if artifact.SHA256 != built.SHA256 || artifact.Size != built.Size {
+		t.Errorf("ResolveArtifact() = %+v, want the manifest %+v", artifact, built)
+	}
+}
+
+func TestResolveArtifactWithoutManifest(t *testing.T) {
+	dir := newTestProject(t, "[package]\nname = \"my-contract\"\n")
+	writeTestFile(t, filepath.Join(dir, "artifacts", "my_contract.wasm"), "\x00asm\x01\x00\x00\x00")
+
+	artifact, err := ResolveArtifact(dir)
+	if err != nil {
+		t.Fatalf("ResolveArtifact() error = %v", err)
+	}
+	if artifact.SHA256 != "" || artifact.Size != 8 {
+		t.Errorf("ResolveArtifact() = %+v, want the size of the file and no digest", artifact)
+	}
+}
+
+func TestResolveArtifactErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup prepares a project with a built artifact
+		setup   func(t *testing.T, dir string)
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "missing artifact",
+			setup: func(t *testing.T, dir string) {
+				os.RemoveAll(filepath.Join(dir, "artifacts"))
+			},
+			wantErr: ErrArtifactNotFound,
+		},
+		{
+			name: "SHA mismatch",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "artifacts", "my_contract.wasm"), "\x00asm\x01\x00\x00\x00modified")
+			},
+			wantErr: ErrStaleArtifact,
+			wantMsg: "modified after it was built",
+		},
+		{
+			name: "source newer than artifact",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "src", "utils.rs"), "// changed\n")
+				future := time.Now().Add(time.Hour)
+				os.Chtimes(filepath.Join(dir, "src", "utils.rs"), future, future)
+			},
+			wantErr: ErrStaleArtifact,
+			wantMsg: filepath.Join("src", "utils.rs"),
+		},
+		{
+			name: "Cargo.toml newer than artifact",
+			setup: func(t *testing.T, dir string) {
+				future := time.Now().Add(time.Hour)
+				os.Chtimes(filepath.Join(dir, "Cargo.toml"), future, future)
+			},
+			wantErr: ErrStaleArtifact,
+			wantMsg: "Cargo.toml",
+		},
+		{
+			name: "artifact of another crate",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, filepath.Join(dir, "Cargo.toml"), "[package]\nname = \"renamed\"\n")
+				past := time.Now().Add(-time.Hour)
+				os.Chtimes(filepath.Join(dir, "Cargo.toml"), past, past)
+			},
+			wantErr: ErrStaleArtifact,
+			wantMsg: "built for crate my_contract",
+		},
+		{
+			name: "no Cargo.toml",
+			setup: func(t *testing.T, dir string) {
+				os.Remove(filepath.Join(dir, "Cargo.toml"))
+			},
+			wantErr: ErrInvalidContractDir,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestProject(t, "[package]\nname = \"my-contract\"\n")
+			buildTestArtifact(t, dir, "my_contract")
+			tt.setup(t, dir)
+
+			_, err := ResolveArtifact(dir)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveArtifact() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("ResolveArtifact() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package contract
+
+import "fmt"
+
+// BuildOptions are the options of a contract build
+type BuildOptions struct {
+	// Release builds with the release profile instead of the debug one
+	Release bool
+	// Features are the cargo features to enable
+	Features []string
+	// Optimize runs wasm-opt on the WASM binary, if it is installed
+	Optimize bool
+}
+
+// Build builds the contract project to WASM and copies the binary to the
+// artifacts directory of the project
+func Build(contractDir string, opts BuildOptions) (*Artifact, error) {
+	if !isValidContractDir(contractDir) {
+		return nil, fmt.Errorf("%w: must contain lib.rs", ErrInvalidContractDir)
+	}
+
+	if err := verifyBuildPrerequisites(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBuild, err)
+	}
+
+	artifact, err := buildWasm(contractDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBuild, err)
+	}
+
+	return artifact, nil
+}
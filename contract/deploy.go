@@ -1,246 +1,158 @@
 package contract
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
 	"github.com/rubixchain/rubix-nexus/utils"
 )
 
-// Deploy handles the contract deployment process
-func Deploy(contractDir string, homeDir string, deployerDid string, deployAmt float64, onStage StageCallback) (*DeploymentResult, error) {
-	// Load config to get API URL
-	cfg, err := config.LoadConfig(homeDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+// Deploy handles the contract deployment process. The deployment is signed
+// with password, the private key password of the deployer DID. When prebuilt
+// is set, its files are deployed without building the contract project, and
+// contractDir may be empty, in which case the deployment is not recorded
+func Deploy(ctx context.Context, cfg *config.Config, contractDir string, prebuilt *Prebuilt, deployerDid string, deployAmt float64, password string, onStage StageCallback) (*DeploymentResult, error) {
+	// Validate the prebuilt files and the contract directory, which the
+	// deployment is recorded in even when the prebuilt files are deployed
+	if prebuilt != nil {
+		if err := prebuilt.validate(); err != nil {
+			return nil, err
+		}
 	}
-
-	// Validate contract directory
-	if !isValidContractDir(contractDir) {
-		return nil, fmt.Errorf("invalid contract directory: must contain lib.rs")
+	if (prebuilt == nil || contractDir != "") && !isValidContractDir(contractDir) {
+		return nil, fmt.Errorf("%w: must contain lib.rs", ErrInvalidContractDir)
 	}
 
-	// Check build prerequisites
-	if err := verifyBuildPrerequisites(); err != nil {
-		return nil, err
-	}
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
 
-	onStage(StageBuild)
-	// Build Rust project to WASM
-	wasmPath, err := buildWasm(contractDir)
+	// Check that the deployer can cover the deployment amount before building
+	accountInfo, err := client.GetAccountInfo(ctx, deployerDid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build WASM: %w", err)
+		return nil, fmt.Errorf("failed to check deployer balance: %w", err)
+	}
+	if accountInfo.RBTAmount < deployAmt {
+		return nil, fmt.Errorf("%w: deployer %s has %v RBT available, %v RBT required", ErrInsufficientBalance, deployerDid, accountInfo.RBTAmount, deployAmt)
 	}
 
-	// Get file paths
-	libPath := filepath.Join(contractDir, "src", "lib.rs")
-	statePath := filepath.Join(filepath.Dir(contractDir), "artifacts", "state.json")
-
-	// Create empty state.json if it doesn't exist
-	if !utils.FileExists(statePath) {
-		if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
-			return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
-		}
-		if err := os.WriteFile(statePath, []byte("{}"), 0644); err != nil {
-			return nil, fmt.Errorf("failed to create state.json: %w", err)
+	stages := newStageTimer(onStage)
+	if prebuilt == nil {
+		prebuilt, err = buildForDeploy(contractDir, stages)
+		if err != nil {
+			return nil, err
 		}
 	}
+	wasmPath, libPath, statePath := prebuilt.WasmPath, prebuilt.SourcePath, prebuilt.StatePath
 
-	onStage(StageGenerate)
-	contractHash, err := generateSmartContract(cfg.Network.DeployerNodeURL, deployerDid, wasmPath, libPath, statePath)
+	stages.start(StageGenerate)
+	contractHash, err := client.GenerateSmartContract(ctx, &rubixapi.GenerateSmartContractRequest{
+		DeployerDID: deployerDid,
+		WasmPath:    wasmPath,
+		LibPath:     libPath,
+		StatePath:   statePath,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate smart contract: %w", err)
 	}
 
-	onStage(StageDeploy)
+	stages.start(StageDeploy)
 
 	// Call deploy-smart-contract API
-	requestID, err := deploySmartContract(cfg.Network.DeployerNodeURL, contractHash, deployerDid, deployAmt)
+	requestID, err := client.DeploySmartContract(ctx, &rubixapi.DeploySmartContractRequest{
+		Comment:            "Contract deployment",
+		DeployerAddr:       deployerDid,
+		QuorumType:         2,
+		RbtAmount:          deployAmt,
+		SmartContractToken: contractHash,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to deploy smart contract: %w", err)
 	}
 
 	// Call signature-response API
-	if err := signatureResponse(cfg.Network.DeployerNodeURL, requestID); err != nil {
-		return nil, fmt.Errorf("failed to process signature response: %w", err)
+	if _, err := client.SignatureResponse(ctx, requestID, password); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignature, err)
 	}
+	stages.stop()
 
-	return &DeploymentResult{
+	result := &DeploymentResult{
 		ContractHash: contractHash,
 		Success:      true,
 		Message:      "Contract deployed successfully",
-	}, nil
-}
-
-func generateSmartContract(baseURL, deployerDid, wasmPath, libPath, statePath string) (string, error) {
-	// Create a buffer to store the multipart form data
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-
-	// Add the deployerDid field
-	if err := writer.WriteField("did", deployerDid); err != nil {
-		return "", fmt.Errorf("failed to add did field: %w", err)
-	}
-
-	// Add the WASM file
-	wasmFile, err := os.Open(wasmPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open WASM file: %w", err)
-	}
-	defer wasmFile.Close()
-	wasmPart, err := writer.CreateFormFile("binaryCodePath", filepath.Base(wasmPath))
-	if err != nil {
-		return "", fmt.Errorf("failed to create WASM form file: %w", err)
-	}
-	if _, err := io.Copy(wasmPart, wasmFile); err != nil {
-		return "", fmt.Errorf("failed to copy WASM file: %w", err)
-	}
-
-	// Add the lib.rs file
-	libFile, err := os.Open(libPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open lib.rs file: %w", err)
-	}
-	defer libFile.Close()
-	libPart, err := writer.CreateFormFile("rawCodePath", filepath.Base(libPath))
-	if err != nil {
-		return "", fmt.Errorf("failed to create lib.rs form file: %w", err)
-	}
-	if _, err := io.Copy(libPart, libFile); err != nil {
-		return "", fmt.Errorf("failed to copy lib.rs file: %w", err)
-	}
-
-	// Add the state.json file
-	stateFile, err := os.Open(statePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open state.json file: %w", err)
-	}
-	defer stateFile.Close()
-	statePart, err := writer.CreateFormFile("schemaFilePath", filepath.Base(statePath))
-	if err != nil {
-		return "", fmt.Errorf("failed to create state.json form file: %w", err)
-	}
-	if _, err := io.Copy(statePart, stateFile); err != nil {
-		return "", fmt.Errorf("failed to copy state.json file: %w", err)
-	}
-
-	// Close the multipart writer
-	if err := writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	// Create the request
-	url := fmt.Sprintf("%s/api/generate-smart-contract", baseURL)
-	req, err := http.NewRequest("POST", url, &requestBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Accept", "multipart/form-data")
-
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse response
-	var apiResp SmartContractAPIResponseV1
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Check response status
-	if !apiResp.Status {
-		return "", fmt.Errorf(apiResp.Message)
+		Stages:       stages.timings,
+	}
+
+	// Record the deployment in the manifest of the contract project. The
+	// contract is deployed either way, so a failure is only reported
+	if contractDir != "" {
+		deployment := &Deployment{
+			Network:      cfg.NetworkName,
+			ContractHash: contractHash,
+			DeployerDID:  deployerDid,
+			DeployAmount: deployAmt,
+			DeployedAt:   time.Now().UTC(),
+		}
+		if deployment.WasmSHA256, err = fileSHA256(wasmPath); err == nil {
+			deployment.SourceSHA256, err = fileSHA256(libPath)
+		}
+		if err == nil {
+			err = recordDeployment(contractDir, deployment)
+		}
+		if err != nil {
+			result.Warning = fmt.Sprintf("deployment not recorded in %s: %v", DeploymentsPath(contractDir), err)
+		}
 	}
 
-	return apiResp.Result, nil
+	return result, nil
 }
 
-func deploySmartContract(baseURL, contractHash, deployerDid string, deployAmt float64) (string, error) {
-	// Create request body
-	requestBody := struct {
-		Comment            string  `json:"comment"`
-		DeployerAddr       string  `json:"deployerAddr"`
-		QuorumType         int     `json:"quorumType"`
-		RbtAmount          float64 `json:"rbtAmount"`
-		SmartContractToken string  `json:"smartContractToken"`
-	}{
-		Comment:            "Contract deployment",
-		DeployerAddr:       deployerDid,
-		QuorumType:         2,
-		RbtAmount:          deployAmt,
-		SmartContractToken: contractHash,
-	}
-
-	// Marshal request body
-	bodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create request
-	requestURL, err := url.JoinPath(baseURL, "/api/deploy-smart-contract")
-	if err != nil {
-		return "", fmt.Errorf("deploy: unable to form request URL")
-	}
-
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
+// buildForDeploy returns the files to deploy from the contract project,
+// creating an empty state.json in the artifacts directory if there is none.
+// An up-to-date artifact is deployed as is, so that one built with other
+// options by contract build is not replaced, otherwise the project is built
+func buildForDeploy(contractDir string, stages *stageTimer) (*Prebuilt, error) {
+	artifact, err := ResolveArtifact(contractDir)
+	if errors.Is(err, ErrArtifactNotFound) || errors.Is(err, ErrStaleArtifact) {
+		// Check build prerequisites
+		if err := verifyBuildPrerequisites(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBuild, err)
+		}
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		stages.start(StageBuild)
+		// Build Rust project to WASM
+		artifact, err = buildWasm(contractDir, BuildOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBuild, err)
+		}
+	} else if err != nil {
+		return nil, err
 	}
 
-	// Parse response
-	var apiResp SmartContractAPIResponseV2
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
+	statePath := filepath.Join(ArtifactsDir(contractDir), "state.json")
 
-	// Check response status
-	if !apiResp.Status {
-		return "", fmt.Errorf(apiResp.Message)
+	// Create empty state.json if it doesn't exist
+	if !utils.FileExists(statePath) {
+		if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create artifacts directory: %w", err)
+		}
+		if err := os.WriteFile(statePath, []byte("{}"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to create state.json: %w", err)
+		}
 	}
 
-	return apiResp.Result.Id, nil
+	return &Prebuilt{
+		WasmPath:   artifact.Path,
+		SourcePath: filepath.Join(contractDir, "src", "lib.rs"),
+		StatePath:  statePath,
+	}, nil
 }
 
 // verifyBuildPrerequisites verifies that all required build tools are available
@@ -282,52 +194,69 @@ func verifyBuildPrerequisites() error {
 	return nil
 }
 
-// buildWasm builds the Rust project targeting wasm32-unknown-unknown
-func buildWasm(projectDir string) (string, error) {
+// buildWasm builds the Rust project targeting wasm32-unknown-unknown and
+// copies the WASM binary to the artifacts directory of the project
+func buildWasm(projectDir string, opts BuildOptions) (*Artifact, error) {
 	// Create target directory if it doesn't exist
 	targetDir := filepath.Join(projectDir, "target")
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create target directory: %w", err)
+		return nil, fmt.Errorf("failed to create target directory: %w", err)
 	}
 
 	// Build the project
-	buildCmd := exec.Command("cargo", "build", "--target", "wasm32-unknown-unknown")
+	buildArgs := []string{"build", "--target", "wasm32-unknown-unknown"}
+	profile := "debug"
+	if opts.Release {
+		buildArgs = append(buildArgs, "--release")
+		profile = "release"
+	}
+	if len(opts.Features) > 0 {
+		buildArgs = append(buildArgs, "--features", strings.Join(opts.Features, ","))
+	}
+	buildCmd := exec.Command("cargo", buildArgs...)
 	buildCmd.Dir = projectDir
 	if output, err := buildCmd.CombinedOutput(); err != nil {
 		// Provide more context for build failures
 		errMsg := string(output)
 		if runtime.GOOS == "windows" && strings.Contains(errMsg, "linker `link.exe` not found") {
-			return "", fmt.Errorf("MSVC build tools not found. Please install Visual Studio Build Tools with C++ support")
+			return nil, fmt.Errorf("MSVC build tools not found. Please install Visual Studio Build Tools with C++ support")
 		}
-		return "", fmt.Errorf("build failed: %s: %w", errMsg, err)
+		return nil, fmt.Errorf("build failed: %s: %w", errMsg, err)
 	}
 
-	// Get the WASM file name from the directory name, replacing hyphens with underscores
-	projectName := strings.ReplaceAll(filepath.Base(projectDir), "-", "_")
-	wasmFile := filepath.Join(projectDir, "target", "wasm32-unknown-unknown", "debug", projectName+".wasm")
+	// Get the WASM file name from the crate name, replacing hyphens with underscores
+	crate, err := crateName(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	wasmFile := filepath.Join(projectDir, "target", "wasm32-unknown-unknown", profile, crate+".wasm")
 
 	// Verify the WASM file was created
 	if !utils.FileExists(wasmFile) {
-		return "", fmt.Errorf("WASM file not found after build at %s", wasmFile)
+		return nil, fmt.Errorf("WASM file not found after build at %s", wasmFile)
 	}
 
-	// Create artifacts directory and copy WASM file
-	artifactsDir := filepath.Join(filepath.Dir(projectDir), "artifacts")
-	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create artifacts directory: %w", err)
-	}
+	artifact := &Artifact{Crate: crate, Profile: profile, Features: opts.Features}
 
-	targetFile := filepath.Join(artifactsDir, projectName+".wasm")
-	input, err := os.ReadFile(wasmFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to read WASM file: %w", err)
+	// Optimize the WASM file if wasm-opt is available
+	if opts.Optimize {
+		if _, err := exec.LookPath("wasm-opt"); err == nil {
+			optimizedFile := strings.TrimSuffix(wasmFile, ".wasm") + ".opt.wasm"
+			optCmd := exec.Command("wasm-opt", "-Oz", wasmFile, "-o", optimizedFile)
+			if output, err := optCmd.CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("wasm-opt failed: %s: %w", string(output), err)
+			}
+			wasmFile = optimizedFile
+			artifact.Optimized = true
+		}
 	}
 
-	if err := os.WriteFile(targetFile, input, 0644); err != nil {
-		return "", fmt.Errorf("failed to copy WASM file to artifacts: %w", err)
+	// Copy the WASM file to the artifacts directory of the project
+	if err := writeArtifact(projectDir, artifact, wasmFile); err != nil {
+		return nil, err
 	}
 
-	return targetFile, nil
+	return artifact, nil
 }
 
 // isValidContractDir checks if the directory contains required contract files
@@ -410,63 +339,3 @@ func isValidContractDir(dir string) bool {
 // 	fmt.Println("Callback URL %v for contract %v registered successfully", callbackURL)
 // 	return nil
 // }
-
-func signatureResponse(baseURL, requestID string) error {
-	// Create request body
-	requestBody := struct {
-		Id       string `json:"id"`
-		Mode     int    `json:"mode"`
-		Password string `json:"password"`
-	}{
-		Id:       requestID,
-		Mode:     0,
-		Password: "mypassword",
-	}
-
-	// Marshal request body
-	bodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create request
-	requestURL, err := url.JoinPath(baseURL, "/api/signature-response")
-	if err != nil {
-		return fmt.Errorf("signature response: unable to form request URL")
-	}
-
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("signature request: failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("signature request: failed to read response: %w", err)
-	}
-
-	// Parse response
-	var apiResp SmartContractAPIResponseV1
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return fmt.Errorf("signature request: failed to parse response: %w", err)
-	}
-
-	// Check response status
-	if !apiResp.Status {
-		return fmt.Errorf(apiResp.Message)
-	}
-
-	return nil
-}
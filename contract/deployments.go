@@ -0,0 +1,123 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deploymentsFile is the name of the deployment manifest of a contract project
+const deploymentsFile = "deployments.json"
+
+// Deployment records a deployment of the contract project
+type Deployment struct {
+	Network      string    `json:"network"`
+	ContractHash string    `json:"contract_hash"`
+	DeployerDID  string    `json:"deployer_did"`
+	DeployAmount float64   `json:"deploy_amount"`
+	WasmSHA256   string    `json:"wasm_sha256"`
+	SourceSHA256 string    `json:"source_sha256"`
+	DeployedAt   time.Time `json:"deployed_at"`
+}
+
+// DeploymentsPath returns the path of the deployment manifest of a contract project
+func DeploymentsPath(contractDir string) string {
+	return filepath.Join(contractDir, deploymentsFile)
+}
+
+// ReadDeployments returns the deployments recorded in the manifest of a
+// contract project, oldest first. No deployments are returned if the
+// manifest does not exist
+func ReadDeployments(contractDir string) ([]*Deployment, error) {
+	content, err := os.ReadFile(DeploymentsPath(contractDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", deploymentsFile, err)
+	}
+
+	var deployments []*Deployment
+	if err := json.Unmarshal(content, &deployments); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", deploymentsFile, err)
+	}
+
+	return deployments, nil
+}
+
+// LatestDeployment returns the latest deployment of a contract project on the network
+func LatestDeployment(contractDir string, network string) (*Deployment, error) {
+	deployments, err := ReadDeployments(contractDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(deployments) - 1; i >= 0; i-- {
+		if deployments[i].Network == network {
+			return deployments[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w on network %q in %s", ErrNoDeployment, network, DeploymentsPath(contractDir))
+}
+
+// VerifyArtifact checks that the WASM artifact of the contract project is
+// the binary of the deployment, so that it stands for the deployed contract
+// in local runs. Deployments recorded without a digest are not checked
+func (d *Deployment) VerifyArtifact(contractDir string) error {
+	if d.WasmSHA256 == "" {
+		return nil
+	}
+
+	artifact, err := ResolveArtifact(contractDir)
+	if err != nil {
+		return err
+	}
+	digest := artifact.SHA256
+	if digest == "" {
+		if digest, err = fileSHA256(artifact.Path); err != nil {
+			return err
+		}
+	}
+	if digest != d.WasmSHA256 {
+		return fmt.Errorf("%w: %s is not the WASM binary deployed as %s, redeploy the contract", ErrStaleArtifact, artifact.Path, d.ContractHash)
+	}
+
+	return nil
+}
+
+// recordDeployment appends a deployment to the manifest of a contract project
+func recordDeployment(contractDir string, deployment *Deployment) error {
+	deployments, err := ReadDeployments(contractDir)
+	if err != nil {
+		return err
+	}
+	deployments = append(deployments, deployment)
+
+	content, err := json.MarshalIndent(deployments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", deploymentsFile, err)
+	}
+	return writeFileAtomic(DeploymentsPath(contractDir), content)
+}
+
+// fileSHA256 returns the hex encoded SHA-256 digest of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", filepath.Base(path), err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
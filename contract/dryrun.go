@@ -0,0 +1,56 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+// DryRun runs the contract message through the local WASM module of the
+// contract project, without contacting the node. hostFns configures the host
+// functions, and the local state they update is not saved. A contract
+// returning an error fails with a WasmError
+func DryRun(contractDir string, contractMsgFile string, hostFns map[string]config.HostFunctionConfig) (*ExecutionResult, error) {
+	contractMsg, err := parseContractMsgFromJSON(contractMsgFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+
+	env, err := newHostEnv(contractDir, hostFns)
+	if err != nil {
+		return nil, err
+	}
+	contractResult, err := callWasm(contractDir, contractMsg, env)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWasmRuntime, err)
+	}
+
+	return &ExecutionResult{
+		ContractResult: contractResult,
+		Success:        true,
+		Message:        "Contract executed locally",
+		DryRun:         true,
+	}, nil
+}
+
+// runPreflight runs the message through the local WASM module before it is
+// submitted. The returned environment holds the updates of the local state,
+// to be saved once the execution is submitted. An error is returned along
+// with a failed preflight result if the contract fails, unless it failed on
+// the call to a disabled host function
+func runPreflight(contractDir string, contractMsg string, hostFns map[string]config.HostFunctionConfig) (*PreflightResult, *hostEnv, error) {
+	env, err := newHostEnv(contractDir, hostFns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contractResult, err := callWasm(contractDir, contractMsg, env)
+	if err == nil {
+		return &PreflightResult{Status: PreflightPassed, ContractResult: contractResult}, env, nil
+	}
+	if env.failedOnDisabledCall(err) {
+		return &PreflightResult{Status: PreflightInconclusive, Error: err.Error()}, env, nil
+	}
+
+	return &PreflightResult{Status: PreflightFailed, Error: err.Error()}, env, fmt.Errorf("%w, execution not submitted: %w", ErrPreflight, err)
+}
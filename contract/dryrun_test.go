@@ -0,0 +1,70 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+func TestRunPreflight(t *testing.T) {
+	dir := newTestContract(t, testContractWat)
+
+	tests := []struct {
+		name       string
+		msg        string
+		wantStatus PreflightStatus
+		wantErr    bool
+	}{
+		{
+			name:       "passed",
+			msg:        `{"done": {}}`,
+			wantStatus: PreflightPassed,
+		},
+		{
+			name:       "contract error",
+			msg:        `{"fail": {}}`,
+			wantStatus: PreflightFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "failed on a disabled host function",
+			msg:        `{"call_api": {}}`,
+			wantStatus: PreflightInconclusive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := runPreflight(dir, tt.msg, map[string]config.HostFunctionConfig{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runPreflight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrPreflight) {
+				t.Errorf("runPreflight() error = %v, want %v", err, ErrPreflight)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+		})
+	}
+}
+
+func TestFailedOnDisabledCall(t *testing.T) {
+	dir := newTestContract(t, testContractWat)
+	env, err := newHostEnv(dir, map[string]config.HostFunctionConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, disabledErr := callWasm(dir, `{"call_api": {}}`, env)
+	if !env.failedOnDisabledCall(disabledErr) {
+		t.Errorf("failedOnDisabledCall(%v) = false, want true", disabledErr)
+	}
+
+	// A contract error after the disabled call does not come from it
+	_, contractErr := callWasm(dir, `{"fail": {}}`, env)
+	if env.failedOnDisabledCall(contractErr) {
+		t.Errorf("failedOnDisabledCall(%v) = true, want false", contractErr)
+	}
+}
@@ -0,0 +1,54 @@
+package contract
+
+import "errors"
+
+// contractErrorPrefix prefixes the errors returned by the WASM bridge when
+// the contract function returns an error. It is the format of
+// WasmModule.CallFunction in go-wasm-bridge v0.1.2, check it when upgrading
+// the bridge, TestCallWasm fails when it no longer matches
+const contractErrorPrefix = "contract execution failed: "
+
+var (
+	// ErrInvalidContractDir is returned when the contract directory is not a Rust contract project
+	ErrInvalidContractDir = errors.New("invalid contract directory")
+	// ErrBuild is returned when the build prerequisites are missing or the contract fails to build
+	ErrBuild = errors.New("failed to build WASM")
+	// ErrArtifactNotFound is returned when the contract project has no WASM artifact
+	ErrArtifactNotFound = errors.New("WASM artifact not found")
+	// ErrStaleArtifact is returned when the WASM artifact does not match the sources of the contract project
+	ErrStaleArtifact = errors.New("WASM artifact is stale")
+	// ErrInvalidArtifact is returned when a prebuilt WASM binary, source or state file is missing or malformed
+	ErrInvalidArtifact = errors.New("invalid prebuilt artifact")
+	// ErrInvalidMessage is returned when the contract message file cannot be read
+	ErrInvalidMessage = errors.New("failed to read contract message file")
+	// ErrSignature is returned when the node fails to sign a deploy or execute request
+	ErrSignature = errors.New("failed to process signature response")
+	// ErrInsufficientBalance is returned when the deployer DID cannot cover the deployment amount
+	ErrInsufficientBalance = errors.New("insufficient RBT balance")
+	// ErrNoDeployment is returned when the deployment manifest has no deployment for the network
+	ErrNoDeployment = errors.New("no deployment recorded")
+	// ErrSnapshotNotFound is returned when a local state snapshot does not exist
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+	// ErrInvalidSnapshot is returned when a local state snapshot name is invalid
+	ErrInvalidSnapshot = errors.New("invalid snapshot name")
+	// ErrNoFixtures is returned when the contract project has no test fixture
+	ErrNoFixtures = errors.New("no test fixtures found")
+	// ErrTestsFailed is returned when fixtures of the contract project fail
+	ErrTestsFailed = errors.New("contract tests failed")
+	// ErrPreflight is returned when the local execution of a message fails before it is submitted
+	ErrPreflight = errors.New("preflight execution failed")
+	// ErrDiverged is returned when the local WASM module fails on blocks of the token chain of a contract
+	ErrDiverged = errors.New("local replay diverged from the token chain")
+	// ErrWasmRuntime is returned when the WASM module cannot be loaded or the contract call fails
+	ErrWasmRuntime = errors.New("failed to call wasm contract")
+)
+
+// WasmError is an error returned by the contract function itself, as
+// opposed to a failure to load or call the WASM module
+type WasmError struct {
+	Message string
+}
+
+func (e *WasmError) Error() string {
+	return "contract returned an error: " + e.Message
+}
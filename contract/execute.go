@@ -1,231 +1,197 @@
 package contract
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/bytecodealliance/wasmtime-go"
 	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
 	"github.com/rubixchain/rubix-wasm/go-wasm-bridge"
 )
 
-// Execute handles the contract execution process
+// Execute handles the contract execution process. The execution is signed
+// with password, the private key password of the executor DID. With
+// preflight, the message is first run through the local WASM module and the
+// execution is not submitted if the contract returns an error, in which case
+// the result reports the failed preflight along with the error. Once executed
+// on the node, the local state is updated with the local result of the
+// message, which is reported as unavailable if the local execution fails
 func Execute(
-	contractHash string, executorDid string, 
-	homeDir string, contractDir string, contractMsgFile string,
+	ctx context.Context, cfg *config.Config, contractHash string,
+	executorDid string, password string, contractDir string, contractMsgFile string,
+	preflight bool,
 ) (*ExecutionResult, error) {
-	// Load config to get API URL
-	cfg, err := config.LoadConfig(homeDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
-	}
-
 	contractMsg, err := parseContractMsgFromJSON(contractMsgFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read contract message file: %w", err)
-	}
-	
-	// Call execute-smart-contract API
-	requestID, err := executeSmartContract(cfg.Network.DeployerNodeURL, contractHash, executorDid, contractMsg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute smart contract: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
 	}
 
-	// Call signature-response API
-	if err := signatureResponse(cfg.Network.DeployerNodeURL, requestID); err != nil {
-		return nil, fmt.Errorf("failed to process signature response: %w", err)
-	}
+	return ExecuteMessage(ctx, cfg, contractHash, executorDid, password, contractDir, contractMsg, preflight)
+}
 
-	contractResult, err := callWasm(contractDir, contractMsg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call wasm contract: %w", err)
+// ExecuteMessage is Execute with the JSON message itself instead of a
+// message file
+func ExecuteMessage(
+	ctx context.Context, cfg *config.Config, contractHash string,
+	executorDid string, password string, contractDir string, contractMsg string,
+	preflight bool,
+) (*ExecutionResult, error) {
+	var (
+		preflightResult *PreflightResult
+		env             *hostEnv
+		err             error
+	)
+	if preflight {
+		preflightResult, env, err = runPreflight(contractDir, contractMsg, cfg.HostFunctions)
+		if err != nil {
+			return &ExecutionResult{Message: "Contract execution not submitted", Preflight: preflightResult}, err
+		}
+	} else if env, err = newHostEnv(contractDir, cfg.HostFunctions); err != nil {
+		return nil, err
 	}
 
-	return &ExecutionResult{
-		ContractResult: contractResult,
-		Success: true,
-		Message: "Contract executed successfully",
-	}, nil
-}
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
 
-
-// Dummy API function (to be implemented with real API call)
-func executeSmartContract(baseURL, contractHash, executorDid, contractMsg string) (string, error) {
-	// Create request body
-	requestBody := struct {
-		Comment            string `json:"comment"`
-		ExecutorAddr       string `json:"executorAddr"`
-		QuorumType         int    `json:"quorumType"`
-		SmartContractData  string `json:"smartContractData"`
-		SmartContractToken string `json:"smartContractToken"`
-	}{
+	// Call execute-smart-contract API
+	requestID, err := client.ExecuteSmartContract(ctx, &rubixapi.ExecuteSmartContractRequest{
 		Comment:            "Contract execution",
 		ExecutorAddr:       executorDid,
 		QuorumType:         2,
 		SmartContractData:  contractMsg,
 		SmartContractToken: contractHash,
-	}
-
-	// Marshal request body
-	bodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create request
-	requestURL, err := url.JoinPath(baseURL, "/api/execute-smart-contract")
-	if err != nil {
-		return "", fmt.Errorf("execute: unable to form request URL")
-	}
-
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to execute smart contract: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+	// Call signature-response API
+	if _, err := client.SignatureResponse(ctx, requestID, password); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignature, err)
 	}
 
-	// Parse response
-	var apiResp SmartContractAPIResponseV2
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	// The execution is committed on the node, a local failure only leaves
+	// its local result unavailable. A passed preflight already holds the
+	// result and the updates of the local state
+	var (
+		contractResult string
+		localErr       error
+	)
+	switch {
+	case preflightResult == nil:
+		contractResult, localErr = callWasm(contractDir, contractMsg, env)
+	case preflightResult.Status == PreflightPassed:
+		contractResult = preflightResult.ContractResult
+	default:
+		localErr = errors.New(preflightResult.Error)
 	}
 
-	// Check response status
-	if !apiResp.Status {
-		return "", fmt.Errorf(apiResp.Message)
+	message := "Contract executed successfully"
+	if localErr != nil {
+		message = fmt.Sprintf("Contract executed successfully, local result unavailable: %v", localErr)
+	} else if err := env.save(); err != nil {
+		message = fmt.Sprintf("Contract executed successfully, local state not saved: %v", err)
 	}
 
-	return apiResp.Result.Id, nil
+	return &ExecutionResult{
+		ContractResult: contractResult,
+		Success:        true,
+		Message:        message,
+		Preflight:      preflightResult,
+		RequestID:      requestID,
+	}, nil
 }
 
-func getSmartContractChainBlocks(baseURL string, contractHash string, onlyLatest bool) ([]*SmartContractBlock, error) {
-	// Create request body
-	requestBody := struct {
-		Latest bool `json:"latest"`
-		Token  string `json:"token"`
-	}{
-		Latest: onlyLatest,
-		Token:  contractHash,
-	}
-
-	// Marshal request body
-	bodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	// Create request
-	requestURL, err := url.JoinPath(baseURL, "/api/get-smart-contract-token-chain-data")
+// callWasm calls the contract function named by the message with the host
+// functions of env
+func callWasm(contractDir string, contractMsg string, env *hostEnv) (string, error) {
+	artifact, err := ResolveArtifact(contractDir)
 	if err != nil {
-		return nil, fmt.Errorf("execute: unable to form request URL")
+		return "", err
 	}
+	wasmModulePath := artifact.Path
 
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// The bridge panics when the function is not exported, check it first
+	if err := checkContractFunction(artifact, contractMsg); err != nil {
+		return "", err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	wasmModule, err := wasmbridge.NewWasmModule(wasmModulePath, env.registry())
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return "", fmt.Errorf("failed to create wasm module: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	contractResult, err := wasmModule.CallFunction(contractMsg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse response
-	var apiResp struct {
-		Status              bool                  `json:"status"`
-		Message             string                `json:"message"`
-		SmartContractBlocks []*SmartContractBlock `json:"SCDataReply"`
-	}
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Check response status
-	if !apiResp.Status {
-		return nil, fmt.Errorf(apiResp.Message)
-	}
-
-	if len(apiResp.SmartContractBlocks) == 0 {
-		return nil, fmt.Errorf("unable to fetch blocks for smart contract token : %v", contractHash)
+		if message, ok := strings.CutPrefix(err.Error(), contractErrorPrefix); ok {
+			return "", &WasmError{Message: message}
+		}
+		return "", fmt.Errorf("failed to call Contract function: %w", err)
 	}
 
-	return apiResp.SmartContractBlocks, nil
+	return contractResult, nil
 }
 
-func callWasm(contractDir string, contractMsg string) (string, error) {
-	wasmModulePath, err := getWasmContractPath(contractDir)
-	if err != nil {
-		return "", fmt.Errorf("failed to get wasm contract path: %w", err)
-	}
-
-	hostFnRegistry := wasmbridge.NewHostFunctionRegistry()
+// contractExports caches the functions exported by the WASM binaries by
+// their SHA-256, so that a binary is compiled outside the bridge only once
+// however many messages it runs
+var contractExports = struct {
+	sync.Mutex
+	funcs map[string]map[string]bool
+}{funcs: make(map[string]map[string]bool)}
 
-	wasmModule, err := wasmbridge.NewWasmModule(wasmModulePath, hostFnRegistry)
-	if err != nil {
-		return "", fmt.Errorf("failed to create wasm module: %w", err)
+// checkContractFunction checks that the WASM binary of the artifact exports
+// the wrapper of the contract function named by the message. Messages that
+// do not name a single function are left to the bridge to reject
+func checkContractFunction(artifact *Artifact, contractMsg string) error {
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(contractMsg), &msg); err != nil || len(msg) != 1 {
+		return nil
 	}
 
-	contractResult, err := wasmModule.CallFunction(contractMsg)
+	funcs, err := exportedFunctions(artifact)
 	if err != nil {
-		return "", fmt.Errorf("failed to call Contract function: %w", err)
+		return err
 	}
-
-	return contractResult, nil
+	for funcName := range msg {
+		if !funcs[funcName+"_"] {
+			return fmt.Errorf("function %s does not exist in the contract", funcName)
+		}
+	}
+	return nil
 }
 
-
-func getWasmContractPath(contractDir string) (string, error) {
-	currentWorkingDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current working directory: %w", err)
+// exportedFunctions returns the names of the functions exported by the WASM
+// binary of the artifact
+func exportedFunctions(artifact *Artifact) (map[string]bool, error) {
+	sha := artifact.SHA256
+	if sha == "" {
+		var err error
+		if sha, err = fileSHA256(artifact.Path); err != nil {
+			return nil, err
+		}
 	}
 
-	artifactsDir := filepath.Join(currentWorkingDir, "artifacts")
+	contractExports.Lock()
+	defer contractExports.Unlock()
+	if funcs, ok := contractExports.funcs[sha]; ok {
+		return funcs, nil
+	}
 
-	entries, err := os.ReadDir(artifactsDir)
+	module, err := wasmtime.NewModuleFromFile(wasmtime.NewEngine(), artifact.Path)
 	if err != nil {
-		return "", fmt.Errorf("failed to read directory: %w", err)
+		return nil, fmt.Errorf("failed to create wasm module: %w", err)
 	}
-
-	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), ".wasm") {
-			return filepath.Join(artifactsDir, entry.Name()), nil
+	funcs := make(map[string]bool)
+	for _, export := range module.Exports() {
+		if export.Type().FuncType() != nil {
+			funcs[export.Name()] = true
 		}
 	}
-
-	return "", fmt.Errorf("no wasm contract found in directory: %v", contractDir)
-}
\ No newline at end of file
+	contractExports.funcs[sha] = funcs
+	return funcs, nil
+}
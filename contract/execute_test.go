@@ -0,0 +1,111 @@
+package contract
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+// testContractWat is a contract with the exports the bridge expects. Its
+// function done returns "done", its function fail returns the contract error
+// "denied" and its function call_api calls do_api_call
+const testContractWat = `(module
+  (import "env" "do_api_call" (func $do_api_call (param i32 i32 i32 i32) (result i32)))
+  (memory (export "memory") 1)
+  (global $next (mut i32) (i32.const 2048))
+  (data (i32.const 1024) "\"denied\"")
+  (data (i32.const 1040) "\"done\"")
+  (func (export "alloc") (param $size i32) (result i32)
+    (local $ptr i32)
+    (local.set $ptr (global.get $next))
+    (global.set $next (i32.add (global.get $next) (local.get $size)))
+    (local.get $ptr))
+  (func (export "dealloc") (param i32 i32))
+  (func (export "done_") (param i32 i32) (param $out i32) (param $outLen i32) (result i32)
+    (i32.store (local.get $out) (i32.const 1040))
+    (i64.store (local.get $outLen) (i64.const 6))
+    (i32.const 0))
+  (func (export "fail_") (param i32 i32) (param $out i32) (param $outLen i32) (result i32)
+    (i32.store (local.get $out) (i32.const 1024))
+    (i64.store (local.get $outLen) (i64.const 8))
+    (i32.const 1))
+  (func (export "call_api_") (param i32 i32) (param $out i32) (param $outLen i32) (result i32)
+    (call $do_api_call (i32.const 1040) (i32.const 6) (local.get $out) (local.get $outLen))))`
+
+// newTestContract creates a contract project whose artifact is the WASM
+// binary of the module in the WAT format
+func newTestContract(t *testing.T, wat string) string {
+	t.Helper()
+
+	wasm, err := wasmtime.Wat2Wasm(wat)
+	if err != nil {
+		t.Fatalf("Wat2Wasm() error = %v", err)
+	}
+	dir := newTestProject(t, "[package]\nname = \"my-contract\"\n")
+	writeTestFile(t, filepath.Join(dir, "artifacts", "my_contract.wasm"), string(wasm))
+	return dir
+}
+
+func TestCallWasm(t *testing.T) {
+	dir := newTestContract(t, testContractWat)
+
+	tests := []struct {
+		name          string
+		msg           string
+		want          string
+		wantWasmError string
+		wantErr       string
+	}{
+		{
+			name: "result",
+			msg:  `{"done": {}}`,
+			want: "done",
+		},
+		{
+			// The message of the contract error is only recognized while
+			// contractErrorPrefix matches the format of the bridge
+			name:          "contract error",
+			msg:           `{"fail": {}}`,
+			wantWasmError: "denied",
+		},
+		{
+			name:    "function not exported",
+			msg:     `{"missing": {}}`,
+			wantErr: "function missing does not exist in the contract",
+		},
+		{
+			name:    "message of two functions rejected by the bridge",
+			msg:     `{"done": {}, "fail": {}}`,
+			wantErr: "failed to call Contract function: input JSON must contain exactly one function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := newHostEnv(dir, map[string]config.HostFunctionConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := callWasm(dir, tt.msg, env)
+			var wasmErr *WasmError
+			switch {
+			case tt.wantWasmError != "":
+				if !errors.As(err, &wasmErr) || wasmErr.Message != tt.wantWasmError {
+					t.Errorf("callWasm() error = %v, want the contract error %q", err, tt.wantWasmError)
+				}
+			case tt.wantErr != "":
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("callWasm() error = %v, want %q", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("callWasm() error = %v", err)
+			case got != tt.want:
+				t.Errorf("callWasm() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
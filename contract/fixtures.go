@@ -0,0 +1,260 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+// fixturesDir is the directory of the contract project holding the test fixtures
+const fixturesDir = "tests"
+
+// Fixture is a test case of a contract: a message and the result or error
+// the contract is expected to return
+type Fixture struct {
+	// Name defaults to the file name of the fixture
+	Name    string          `json:"name,omitempty"`
+	Message json.RawMessage `json:"message"`
+	// State is the local state the message is run on. It defaults to the
+	// initial state artifacts/state.json
+	State LocalState `json:"state,omitempty"`
+	// ExpectedResult is compared to the contract result, as JSON when it is
+	// not a string
+	ExpectedResult json.RawMessage `json:"expected_result,omitempty"`
+	// ExpectedError must be contained in the error returned by the contract
+	ExpectedError *string `json:"expected_error,omitempty"`
+}
+
+// TestResult is the outcome of running a fixture
+type TestResult struct {
+	Name     string        `json:"name"`
+	File     string        `json:"file"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"-"`
+	// Failure explains why the fixture failed
+	Failure string `json:"failure,omitempty"`
+	// Diff compares the expected and actual results line by line, see diffLines
+	Diff string `json:"diff,omitempty"`
+}
+
+// MarshalJSON encodes the result with the duration in milliseconds
+func (r *TestResult) MarshalJSON() ([]byte, error) {
+	type testResult TestResult
+	return json.Marshal(struct {
+		*testResult
+		DurationMs int64 `json:"duration_ms"`
+	}{
+		testResult: (*testResult)(r),
+		DurationMs: r.Duration.Milliseconds(),
+	})
+}
+
+// TestReport is the outcome of running the fixtures of a contract project
+type TestReport struct {
+	Tests    []*TestResult `json:"tests"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON encodes the report with the duration in milliseconds
+func (r *TestReport) MarshalJSON() ([]byte, error) {
+	type testReport TestReport
+	return json.Marshal(struct {
+		*testReport
+		DurationMs int64 `json:"duration_ms"`
+	}{
+		testReport: (*testReport)(r),
+		DurationMs: r.Duration.Milliseconds(),
+	})
+}
+
+// FixtureFiles returns the fixture files of the contract project, tests/*.json
+func FixtureFiles(contractDir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(contractDir, fixturesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoFixtures, filepath.Join(contractDir, fixturesDir))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// RunTests runs the fixture files through the local WASM module of the
+// contract project. Every fixture starts from its own state and an empty
+// ledger, and the local state of the project is left untouched
+func RunTests(contractDir string, files []string, hostFns map[string]config.HostFunctionConfig) (*TestReport, error) {
+	// Fail once for all the fixtures if the artifact cannot be run
+	if _, err := ResolveArtifact(contractDir); err != nil {
+		return nil, err
+	}
+
+	report := &TestReport{}
+	start := time.Now()
+	for _, file := range files {
+		result, err := runFixture(contractDir, file, hostFns)
+		if err != nil {
+			return nil, err
+		}
+		report.Tests = append(report.Tests, result)
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	report.Duration = time.Since(start)
+
+	return report, nil
+}
+
+// runFixture runs a fixture file. Invalid fixtures fail, an error is only
+// returned when the host functions cannot be set up
+func runFixture(contractDir string, file string, hostFns map[string]config.HostFunctionConfig) (*TestResult, error) {
+	result := &TestResult{
+		Name: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		File: file,
+	}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	fixture, err := readFixture(file)
+	if err != nil {
+		result.Failure = err.Error()
+		return result, nil
+	}
+	if fixture.Name != "" {
+		result.Name = fixture.Name
+	}
+
+	env, err := newHostEnv(contractDir, hostFns)
+	if err != nil {
+		return nil, err
+	}
+	env.ledger = &ledger{NFTs: make(map[string]*LocalNFT), FTs: make(map[string]map[string]int32)}
+	env.state = fixture.State
+	if env.state == nil {
+		if env.state, err = readInitialState(contractDir); err != nil {
+			return nil, err
+		}
+	}
+
+	contractResult, callErr := callWasm(contractDir, string(fixture.Message), env)
+	switch {
+	case fixture.ExpectedError != nil && callErr == nil:
+		result.Failure = fmt.Sprintf("expected error %q, the contract returned %q", *fixture.ExpectedError, contractResult)
+	case fixture.ExpectedError != nil:
+		message := callErr.Error()
+		var wasmErr *WasmError
+		if errors.As(callErr, &wasmErr) {
+			message = wasmErr.Message
+		}
+		if !strings.Contains(message, *fixture.ExpectedError) {
+			result.Failure = "unexpected error"
+			result.Diff = diffLines(*fixture.ExpectedError, message)
+		}
+	case callErr != nil:
+		result.Failure = callErr.Error()
+	default:
+		expected, actual := formatResults(fixture.ExpectedResult, contractResult)
+		if expected != actual {
+			result.Failure = "unexpected result"
+			result.Diff = diffLines(expected, actual)
+		}
+	}
+	result.Passed = result.Failure == ""
+
+	return result, nil
+}
+
+// readFixture reads and checks a fixture file
+func readFixture(file string) (*Fixture, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	if len(fixture.Message) == 0 {
+		return nil, errors.New("invalid fixture: message is required")
+	}
+	if (len(fixture.ExpectedResult) == 0) == (fixture.ExpectedError == nil) {
+		return nil, errors.New("invalid fixture: exactly one of expected_result and expected_error is required")
+	}
+	return &fixture, nil
+}
+
+// formatResults formats the expected and actual results for comparison. A
+// string is expected as is, other JSON values are compared to the result
+// parsed as JSON, both indented
+func formatResults(expected json.RawMessage, actual string) (string, string) {
+	var expectedString string
+	if err := json.Unmarshal(expected, &expectedString); err == nil {
+		return expectedString, actual
+	}
+
+	var expectedValue, actualValue interface{}
+	json.Unmarshal(expected, &expectedValue)
+	expectedJSON, _ := json.MarshalIndent(expectedValue, "", "  ")
+	if err := json.Unmarshal([]byte(actual), &actualValue); err != nil {
+		return string(expectedJSON), actual
+	}
+	actualJSON, _ := json.MarshalIndent(actualValue, "", "  ")
+	return string(expectedJSON), string(actualJSON)
+}
+
+// diffLines returns the lines of expected, prefixed by "-", and of actual,
+// prefixed by "+", that are not part of their longest common subsequence.
+// Common lines are prefixed by a space
+func diffLines(expected string, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff bytes.Buffer
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Fprintf(&diff, "  %s\n", a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&diff, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&diff, "+ %s\n", b[j])
+			j++
+		}
+	}
+	return diff.String()
+}
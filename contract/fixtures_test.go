@@ -0,0 +1,186 @@
+package contract
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		want     string
+	}{
+		{
+			name:     "equal",
+			expected: "a\nb",
+			actual:   "a\nb",
+			want:     "  a\n  b\n",
+		},
+		{
+			name:     "changed line",
+			expected: "a\nb\nc",
+			actual:   "a\nx\nc",
+			want:     "  a\n- b\n+ x\n  c\n",
+		},
+		{
+			name:     "inserted and removed lines",
+			expected: "a\nb\nc\nd",
+			actual:   "b\nc\ne\nd",
+			want:     "- a\n  b\n  c\n+ e\n  d\n",
+		},
+		{
+			name:     "no common line",
+			expected: "a",
+			actual:   "b\nc",
+			want:     "- a\n+ b\n+ c\n",
+		},
+		{
+			name:     "indented JSON",
+			expected: "{\n  \"count\": 1,\n  \"owner\": \"alice\"\n}",
+			actual:   "{\n  \"count\": 2,\n  \"owner\": \"alice\"\n}",
+			want:     "  {\n-   \"count\": 1,\n+   \"count\": 2,\n    \"owner\": \"alice\"\n  }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("diffLines() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatResults(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  string
+		actual    string
+		wantEqual bool
+	}{
+		{
+			name:      "string compared as is",
+			expected:  `"hello"`,
+			actual:    "hello",
+			wantEqual: true,
+		},
+		{
+			name:     "string holding JSON is not parsed",
+			expected: `"{\"count\": 1}"`,
+			actual:   `{"count":1}`,
+		},
+		{
+			name:      "object with other key order and spacing",
+			expected:  `{"owner": "alice", "count": 1}`,
+			actual:    `{"count":1,"owner":"alice"}`,
+			wantEqual: true,
+		},
+		{
+			name:     "object with another value",
+			expected: `{"count": 1}`,
+			actual:   `{"count": 2}`,
+		},
+		{
+			name:      "number",
+			expected:  `42`,
+			actual:    "42",
+			wantEqual: true,
+		},
+		{
+			name:     "number against a string result",
+			expected: `42`,
+			actual:   `"42"`,
+		},
+		{
+			name:     "JSON against a result that is not JSON",
+			expected: `{"count": 1}`,
+			actual:   "count: 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, actual := formatResults(json.RawMessage(tt.expected), tt.actual)
+			if (expected == actual) != tt.wantEqual {
+				t.Errorf("formatResults() = %q, %q, want equal %v", expected, actual, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestFormatResultsIndentsJSON(t *testing.T) {
+	expected, actual := formatResults(json.RawMessage(`{"b": [1], "a": 1}`), `{"a":1,"b":[2]}`)
+	if want := "{\n  \"a\": 1,\n  \"b\": [\n    1\n  ]\n}"; expected != want {
+		t.Errorf("expected = %q, want %q", expected, want)
+	}
+	if want := "{\n  \"a\": 1,\n  \"b\": [\n    2\n  ]\n}"; actual != want {
+		t.Errorf("actual = %q, want %q", actual, want)
+	}
+}
+
+func TestReadFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "expected result",
+			content: `{"message": {"add": {"a": 1}}, "expected_result": 2}`,
+		},
+		{
+			name:    "expected string result",
+			content: `{"message": {"greet": {}}, "expected_result": "hello"}`,
+		},
+		{
+			name:    "expected error",
+			content: `{"message": {"add": {}}, "expected_error": "missing"}`,
+		},
+		{
+			name:    "empty expected error",
+			content: `{"message": {"add": {}}, "expected_error": ""}`,
+		},
+		{
+			name:    "both expected result and error",
+			content: `{"message": {"add": {}}, "expected_result": 2, "expected_error": "missing"}`,
+			wantErr: "exactly one of expected_result and expected_error",
+		},
+		{
+			name:    "neither expected result nor error",
+			content: `{"message": {"add": {}}}`,
+			wantErr: "exactly one of expected_result and expected_error",
+		},
+		{
+			name:    "no message",
+			content: `{"expected_result": 2}`,
+			wantErr: "message is required",
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"message": `,
+			wantErr: "failed to parse fixture",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "fixture.json")
+			writeTestFile(t, file, tt.content)
+
+			_, err := readFixture(file)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("readFixture() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readFixture() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
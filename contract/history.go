@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+)
+
+// Block is a block of a smart contract token chain
+type Block struct {
+	BlockNo uint64 `json:"block_no"`
+	BlockID string `json:"block_id"`
+	// Data is the smart contract data recorded by the block. JSON payloads
+	// are kept as JSON, other payloads are encoded as a JSON string
+	Data json.RawMessage `json:"data"`
+}
+
+// History returns the blocks of the token chain of a smart contract, oldest
+// first. If onlyLatest is set, only the latest block is returned
+func History(ctx context.Context, cfg *config.Config, contractHash string, onlyLatest bool) ([]*Block, error) {
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
+
+	chainBlocks, err := client.GetSmartContractTokenChainData(ctx, contractHash, onlyLatest)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]*Block, 0, len(chainBlocks))
+	for _, chainBlock := range chainBlocks {
+		blockNo, err := strconv.ParseUint(chainBlock.BlockNo.String(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number %q of block %s: %w", chainBlock.BlockNo, chainBlock.BlockId, err)
+		}
+
+		blocks = append(blocks, &Block{
+			BlockNo: blockNo,
+			BlockID: chainBlock.BlockId,
+			Data:    decodeBlockData(chainBlock.SmartContractData),
+		})
+	}
+
+	// The node does not guarantee the order of the blocks
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return blocks[i].BlockNo < blocks[j].BlockNo
+	})
+
+	return blocks, nil
+}
+
+// decodeBlockData keeps JSON smart contract data as is, and encodes other
+// data as a JSON string. Empty data is null
+func decodeBlockData(data string) json.RawMessage {
+	trimmed := strings.TrimSpace(data)
+	if trimmed == "" {
+		return json.RawMessage("null")
+	}
+	if json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+
+	encoded, _ := json.Marshal(data)
+	return encoded
+}
@@ -0,0 +1,38 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+func TestHistoryOrdersBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": true, "SCDataReply": [
+			{"BlockNo": "10", "BlockId": "c", "SmartContractData": "{\"add\": {}}"},
+			{"BlockNo": 2, "BlockId": "b", "SmartContractData": "{\"add\": {}}"},
+			{"BlockNo": "1", "BlockId": "a", "SmartContractData": ""}
+		]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Network: config.NetworkConfig{DeployerNodeURL: server.URL}}
+	blocks, err := History(context.Background(), cfg, "QmContract", false)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	var ids string
+	for _, block := range blocks {
+		ids += block.BlockID
+	}
+	if ids != "abc" {
+		t.Errorf("History() blocks = %s, want them ordered by block number", ids)
+	}
+	if string(blocks[0].Data) != "null" || string(blocks[1].Data) != `{"add": {}}` {
+		t.Errorf("History() data = %s, %s", blocks[0].Data, blocks[1].Data)
+	}
+}
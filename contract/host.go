@@ -0,0 +1,447 @@
+package contract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/ft"
+	"github.com/rubixchain/rubix-wasm/go-wasm-bridge/host/nft"
+	wasmutils "github.com/rubixchain/rubix-wasm/go-wasm-bridge/utils"
+)
+
+// Host functions of the WASM bridge, and the ones of rubix-nexus giving
+// access to the local state
+const (
+	hostDoAPICall     = "do_api_call"
+	hostDoMintNFT     = "do_mint_nft"
+	hostDoTransferNFT = "do_transfer_nft"
+	hostDoMintFT      = "do_mint_ft"
+	hostDoTransferFT  = "do_transfer_ft"
+	hostGetState      = "get_state"
+	hostSetState      = "set_state"
+)
+
+// hostFunctionNames lists the host functions defined for every local
+// execution, in registration order
+var hostFunctionNames = []string{hostDoAPICall, hostDoMintNFT, hostDoTransferNFT, hostDoMintFT, hostDoTransferFT, hostGetState, hostSetState}
+
+// apiCallTimeout bounds the HTTP requests of the local do_api_call
+const apiCallTimeout = 30 * time.Second
+
+// hostHandler handles the input of a host function call and returns the
+// response written back to the contract
+type hostHandler func(input []byte) (string, error)
+
+// hostEnv holds the host functions of a local execution and the local state
+// of the contract project they act on
+type hostEnv struct {
+	contractDir string
+	hostFns     map[string]config.HostFunctionConfig
+	ledger      *ledger
+	state       LocalState
+	// ledgerChanged and stateChanged are set when a host function updated
+	// the ledger or the state
+	ledgerChanged bool
+	stateChanged  bool
+	// disabledCall is the error the first call to a disabled host function
+	// trapped the contract with
+	disabledCall string
+}
+
+// newHostEnv loads the local state of the contract project. hostFns
+// configures the host functions by name: they run the local implementation
+// by default, and other host functions imported by the contract can be mocked
+func newHostEnv(contractDir string, hostFns map[string]config.HostFunctionConfig) (*hostEnv, error) {
+	for name, hostFn := range hostFns {
+		if !isHostFunction(name) && hostFn.Mode != config.HostFunctionMock {
+			return nil, fmt.Errorf("%w: unknown host function %q can only be mocked", config.ErrInvalidConfig, name)
+		}
+	}
+
+	ledger, err := readLedger(contractDir)
+	if err != nil {
+		return nil, err
+	}
+	state, err := ReadLocalState(contractDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hostEnv{contractDir: contractDir, hostFns: hostFns, ledger: ledger, state: state}, nil
+}
+
+// clone returns a copy of the environment with its own copy of the ledger
+// and state, so that a failed call can be discarded
+func (e *hostEnv) clone() (*hostEnv, error) {
+	next := &hostEnv{
+		contractDir:   e.contractDir,
+		hostFns:       e.hostFns,
+		ledger:        &ledger{},
+		ledgerChanged: e.ledgerChanged,
+		stateChanged:  e.stateChanged,
+	}
+
+	content, err := json.Marshal(e.ledger)
+	if err == nil {
+		err = json.Unmarshal(content, next.ledger)
+	}
+	if err == nil {
+		content, err = json.Marshal(e.state)
+	}
+	if err == nil {
+		err = json.Unmarshal(content, &next.state)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy local state: %w", err)
+	}
+	return next, nil
+}
+
+// registry returns the registry of the host functions of the execution
+func (e *hostEnv) registry() *wasmbridge.HostFunctionRegistry {
+	registry := &wasmbridge.HostFunctionRegistry{}
+	for _, name := range hostFunctionNames {
+		registry.Register(&localHostFunction{name: name, handle: e.handler(name)})
+	}
+	for name, hostFn := range e.hostFns {
+		if !isHostFunction(name) {
+			registry.Register(&localHostFunction{name: name, handle: mockHandler(hostFn)})
+		}
+	}
+	return registry
+}
+
+// save persists the local state updated by the host functions
+func (e *hostEnv) save() error {
+	return e.write(e.ledgerChanged, e.stateChanged)
+}
+
+// write replaces the files of the ledger and of the state, as selected, in
+// the contract project. Each file is replaced atomically, and a failed write
+// puts back the files already replaced
+func (e *hostEnv) write(ledger bool, state bool) error {
+	var writes []fileWrite
+	if ledger {
+		write, err := e.ledger.localFile(e.contractDir)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+	if state {
+		write, err := e.state.localFile(e.contractDir)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, write)
+	}
+	return writeFilesAtomic(writes)
+}
+
+// handler returns the handler of a host function according to its mode
+func (e *hostEnv) handler(name string) hostHandler {
+	hostFn := e.hostFns[name]
+	switch hostFn.Mode {
+	case config.HostFunctionMock:
+		return mockHandler(hostFn)
+	case config.HostFunctionDisabled:
+		return e.disabledHandler(name, "disabled in the configuration")
+	}
+
+	switch name {
+	case hostDoAPICall:
+		// Local executions only reach the network when explicitly allowed
+		if hostFn.Mode != config.HostFunctionLocal {
+			return e.disabledHandler(name, "sends network requests, mock it or set its mode to local in the configuration")
+		}
+		return doAPICall
+	case hostDoMintNFT:
+		return e.doMintNFT
+	case hostDoTransferNFT:
+		return e.doTransferNFT
+	case hostDoMintFT:
+		return e.doMintFT
+	case hostDoTransferFT:
+		return e.doTransferFT
+	case hostGetState:
+		return e.getState
+	default:
+		return e.setState
+	}
+}
+
+// disabledHandler returns the handler of a disabled host function, failing
+// the call with the reason
+func (e *hostEnv) disabledHandler(name string, reason string) hostHandler {
+	return func([]byte) (string, error) {
+		if e.disabledCall == "" {
+			e.disabledCall = hostFunctionError(name, reason)
+		}
+		return "", errors.New(reason)
+	}
+}
+
+// failedOnDisabledCall checks if the error of a local execution is the trap
+// of a call to a disabled host function
+func (e *hostEnv) failedOnDisabledCall(err error) bool {
+	return e.disabledCall != "" && strings.Contains(err.Error(), e.disabledCall)
+}
+
+// isHostFunction checks if the host function is defined for every local execution
+func isHostFunction(name string) bool {
+	for _, hostFn := range hostFunctionNames {
+		if hostFn == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mockHandler returns the configured response or error of a mocked host function
+func mockHandler(hostFn config.HostFunctionConfig) hostHandler {
+	return func([]byte) (string, error) {
+		if hostFn.Error != "" {
+			return "", errors.New(hostFn.Error)
+		}
+		return hostFn.Response, nil
+	}
+}
+
+// doAPICall sends a GET request to the URL and returns the response body
+func doAPICall(input []byte) (string, error) {
+	client := &http.Client{Timeout: apiCallTimeout}
+	resp, err := client.Get(string(input))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	return string(body), nil
+}
+
+// doMintNFT records a new NFT owned by the minting DID in the ledger
+func (e *hostEnv) doMintNFT(input []byte) (string, error) {
+	var data nft.MintNFTData
+	if err := json.Unmarshal(input, &data); err != nil {
+		return "", fmt.Errorf("invalid input: %w", err)
+	}
+	if data.Did == "" {
+		return "", errors.New("the minting DID is required")
+	}
+
+	digest := sha256.Sum256([]byte(data.Did + "\x00" + data.Metadata + "\x00" + data.Artifact))
+	nftID := hex.EncodeToString(digest[:])
+	if _, ok := e.ledger.NFTs[nftID]; ok {
+		return "", fmt.Errorf("NFT %s is already minted", nftID)
+	}
+	e.ledger.NFTs[nftID] = &LocalNFT{Owner: data.Did, Metadata: data.Metadata, Artifact: data.Artifact}
+	e.ledgerChanged = true
+
+	return nodeResponse("NFT created successfully", nftID)
+}
+
+// doTransferNFT transfers an NFT of the ledger to the receiver
+func (e *hostEnv) doTransferNFT(input []byte) (string, error) {
+	var data nft.TransferNFTData
+	if err := json.Unmarshal(input, &data); err != nil {
+		return "", fmt.Errorf("invalid input: %w", err)
+	}
+
+	token, ok := e.ledger.NFTs[data.NFT]
+	if !ok {
+		return "", fmt.Errorf("NFT %s is not minted", data.NFT)
+	}
+	if token.Owner != data.Owner {
+		return "", fmt.Errorf("NFT %s is not owned by %s", data.NFT, data.Owner)
+	}
+	if data.Receiver == "" {
+		return "", errors.New("the receiver DID is required")
+	}
+	token.Owner = data.Receiver
+	token.Value = data.NFTValue
+	token.Data = data.NFTData
+	e.ledgerChanged = true
+
+	return "success", nil
+}
+
+// doMintFT credits the minting DID with new FTs in the ledger
+func (e *hostEnv) doMintFT(input []byte) (string, error) {
+	var data ft.MintFTData
+	if err := json.Unmarshal(input, &data); err != nil {
+		return "", fmt.Errorf("invalid input: %w", err)
+	}
+	if data.Did == "" || data.FtName == "" {
+		return "", errors.New("the minting DID and FT name are required")
+	}
+	if data.FtCount <= 0 {
+		return "", fmt.Errorf("invalid FT count %d", data.FtCount)
+	}
+
+	balances := e.ftBalances(data.Did, data.FtName)
+	balances[data.Did] += data.FtCount
+	e.ledgerChanged = true
+
+	return nodeResponse("FT created successfully", nil)
+}
+
+// doTransferFT moves FTs of the ledger from the sender to the receiver
+func (e *hostEnv) doTransferFT(input []byte) (string, error) {
+	var data ft.TransferFTData
+	if err := json.Unmarshal(input, &data); err != nil {
+		return "", fmt.Errorf("invalid input: %w", err)
+	}
+	if data.FTCount <= 0 {
+		return "", fmt.Errorf("invalid FT count %d", data.FTCount)
+	}
+	if data.Receiver == "" {
+		return "", errors.New("the receiver DID is required")
+	}
+
+	balances := e.ftBalances(data.CreatorDID, data.FTName)
+	if balances[data.Sender] < data.FTCount {
+		return "", fmt.Errorf("%s holds %d %s FTs, %d required", data.Sender, balances[data.Sender], data.FTName, data.FTCount)
+	}
+	balances[data.Sender] -= data.FTCount
+	balances[data.Receiver] += data.FTCount
+	e.ledgerChanged = true
+
+	return "success", nil
+}
+
+// getState returns the JSON value of a key of the local state, null if the
+// key is not set. An empty key returns the whole state
+func (e *hostEnv) getState(input []byte) (string, error) {
+	key := string(input)
+	if key == "" {
+		state, err := json.Marshal(e.state)
+		if err != nil {
+			return "", err
+		}
+		return string(state), nil
+	}
+
+	value, ok := e.state[key]
+	if !ok {
+		return "null", nil
+	}
+	return string(value), nil
+}
+
+// setState sets a key of the local state to a JSON value. A null value
+// removes the key
+func (e *hostEnv) setState(input []byte) (string, error) {
+	var data struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(input, &data); err != nil {
+		return "", fmt.Errorf("invalid input: %w", err)
+	}
+	if data.Key == "" {
+		return "", errors.New("the state key is required")
+	}
+
+	if len(data.Value) == 0 || string(data.Value) == "null" {
+		delete(e.state, data.Key)
+	} else {
+		e.state[data.Key] = data.Value
+	}
+	e.stateChanged = true
+
+	return "success", nil
+}
+
+// ftBalances returns the balances of the FT, by owner DID
+func (e *hostEnv) ftBalances(creatorDID string, name string) map[string]int32 {
+	key := creatorDID + "/" + name
+	if e.ledger.FTs[key] == nil {
+		e.ledger.FTs[key] = make(map[string]int32)
+	}
+	return e.ledger.FTs[key]
+}
+
+// nodeResponse returns a response shaped like the ones of the Rubix node APIs
+func nodeResponse(message string, result interface{}) (string, error) {
+	response, err := json.Marshal(map[string]interface{}{
+		"status":  true,
+		"message": message,
+		"result":  result,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(response), nil
+}
+
+// localHostFunction is a host function taking an input and writing a
+// response back to the contract, like the host functions of the WASM bridge
+type localHostFunction struct {
+	name      string
+	handle    hostHandler
+	allocFunc *wasmtime.Func
+}
+
+func (h *localHostFunction) Name() string {
+	return h.name
+}
+
+func (h *localHostFunction) FuncType() *wasmtime.FuncType {
+	return wasmtime.NewFuncType(
+		[]*wasmtime.ValType{
+			wasmtime.NewValType(wasmtime.KindI32), // input_ptr
+			wasmtime.NewValType(wasmtime.KindI32), // input_len
+			wasmtime.NewValType(wasmtime.KindI32), // resp_ptr_ptr
+			wasmtime.NewValType(wasmtime.KindI32), // resp_len_ptr
+		},
+		[]*wasmtime.ValType{wasmtime.NewValType(wasmtime.KindI32)},
+	)
+}
+
+func (h *localHostFunction) Initialize(allocFunc, deallocFunc *wasmtime.Func, memory *wasmtime.Memory, nodeAddress string, quorumType int) {
+	h.allocFunc = allocFunc
+}
+
+func (h *localHostFunction) Callback() host.HostFunctionCallBack {
+	return func(caller *wasmtime.Caller, args []wasmtime.Val) ([]wasmtime.Val, *wasmtime.Trap) {
+		inputArgs, outputArgs := wasmutils.HostFunctionParamExtraction(args, true, true)
+		if inputArgs == nil {
+			return wasmutils.HandleError(hostFunctionError(h.name, "invalid number of arguments"))
+		}
+
+		input, _, err := wasmutils.ExtractDataFromWASM(caller, inputArgs)
+		if err != nil {
+			return wasmutils.HandleError(hostFunctionError(h.name, err))
+		}
+
+		response, err := h.handle(input)
+		if err != nil {
+			return wasmutils.HandleError(hostFunctionError(h.name, err))
+		}
+
+		if err := wasmutils.UpdateDataToWASM(caller, h.allocFunc, response, outputArgs); err != nil {
+			return wasmutils.HandleError(hostFunctionError(h.name, err))
+		}
+		return wasmutils.HandleOk()
+	}
+}
+
+// hostFunctionError formats the error a host function traps the contract with
+func hostFunctionError(name string, err interface{}) string {
+	return fmt.Sprintf("host function %s: %v", name, err)
+}
@@ -0,0 +1,66 @@
+package contract
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+func TestDoAPICallModes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"price": 7}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		hostFn       *config.HostFunctionConfig
+		want         string
+		wantErr      bool
+		wantDisabled bool
+	}{
+		{
+			name:         "not configured",
+			wantErr:      true,
+			wantDisabled: true,
+		},
+		{
+			name:   "local",
+			hostFn: &config.HostFunctionConfig{Mode: config.HostFunctionLocal},
+			want:   `{"price": 7}`,
+		},
+		{
+			name:   "mock",
+			hostFn: &config.HostFunctionConfig{Mode: config.HostFunctionMock, Response: `{"price": 42}`},
+			want:   `{"price": 42}`,
+		},
+		{
+			name:         "disabled",
+			hostFn:       &config.HostFunctionConfig{Mode: config.HostFunctionDisabled},
+			wantErr:      true,
+			wantDisabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &hostEnv{hostFns: map[string]config.HostFunctionConfig{}}
+			if tt.hostFn != nil {
+				env.hostFns[hostDoAPICall] = *tt.hostFn
+			}
+
+			got, err := env.handler(hostDoAPICall)([]byte(server.URL))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("do_api_call error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("do_api_call = %q, want %q", got, tt.want)
+			}
+			if disabled := env.disabledCall != ""; disabled != tt.wantDisabled {
+				t.Errorf("disabled call recorded = %v, want %v", disabled, tt.wantDisabled)
+			}
+		})
+	}
+}
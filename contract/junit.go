@@ -0,0 +1,77 @@
+package contract
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML document holding a single
+// test suite named suite
+func (r *TestReport) WriteJUnit(w io.Writer, suite string) error {
+	testSuite := junitTestSuite{
+		Name:     suite,
+		Tests:    len(r.Tests),
+		Failures: r.Failed,
+		Time:     fmt.Sprintf("%.3f", r.Duration.Seconds()),
+	}
+	for _, test := range r.Tests {
+		testCase := junitTestCase{
+			Name:      test.Name,
+			ClassName: suite,
+			File:      test.File,
+			Time:      fmt.Sprintf("%.3f", test.Duration.Seconds()),
+		}
+		if !test.Passed {
+			testCase.Failure = &junitFailure{Message: test.Failure, Text: test.Diff}
+		}
+		testSuite.TestCases = append(testSuite.TestCases, testCase)
+	}
+
+	report := junitTestSuites{
+		Tests:    testSuite.Tests,
+		Failures: testSuite.Failures,
+		Time:     testSuite.Time,
+		Suites:   []junitTestSuite{testSuite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,49 @@
+package contract
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	report := &TestReport{
+		Tests: []*TestResult{
+			{
+				Name:     "add",
+				File:     "tests/add.json",
+				Passed:   true,
+				Duration: 12 * time.Millisecond,
+			},
+			{
+				Name:     "transfer <limit>",
+				File:     "tests/transfer.json",
+				Duration: 1500 * time.Millisecond,
+				Failure:  "unexpected result",
+				Diff:     "- \"ok\"\n+ \"denied & logged\"\n",
+			},
+		},
+		Passed:   1,
+		Failed:   1,
+		Duration: 1512 * time.Millisecond,
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJUnit(&buf, "counter"); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="2" failures="1" time="1.512">
+  <testsuite name="counter" tests="2" failures="1" time="1.512">
+    <testcase name="add" classname="counter" file="tests/add.json" time="0.012"></testcase>
+    <testcase name="transfer &lt;limit&gt;" classname="counter" file="tests/transfer.json" time="1.500">
+      <failure message="unexpected result">- &#34;ok&#34;&#xA;+ &#34;denied &amp; logged&#34;&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteJUnit() =\n%s\nwant\n%s", got, want)
+	}
+}
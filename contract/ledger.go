@@ -0,0 +1,154 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rubixchain/rubix-nexus/utils"
+)
+
+const (
+	// localDir is the directory of the contract project holding the local
+	// state of the contract
+	localDir = ".nexus"
+	// ledgerFile records the NFTs and FTs handled by the local host functions
+	ledgerFile = "ledger.json"
+)
+
+// LocalDir returns the directory holding the local state of the contract project
+func LocalDir(contractDir string) string {
+	return filepath.Join(contractDir, localDir)
+}
+
+// LocalNFT is an NFT minted by a local execution of the contract
+type LocalNFT struct {
+	Owner    string  `json:"owner"`
+	Metadata string  `json:"metadata"`
+	Artifact string  `json:"artifact"`
+	Value    float64 `json:"value,omitempty"`
+	Data     string  `json:"data,omitempty"`
+}
+
+// ledger holds the NFTs and FTs minted and transferred by the local host
+// functions, so that local executions see the effect of the previous ones
+type ledger struct {
+	// NFTs are keyed by NFT ID
+	NFTs map[string]*LocalNFT `json:"nfts"`
+	// FTs are keyed by "<creator DID>/<FT name>", then by owner DID
+	FTs map[string]map[string]int32 `json:"fts"`
+}
+
+// readLedger reads the ledger of the contract project. A missing ledger is empty
+func readLedger(contractDir string) (*ledger, error) {
+	l := &ledger{}
+	content, err := os.ReadFile(filepath.Join(LocalDir(contractDir), ledgerFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read local ledger: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, l); err != nil {
+			return nil, fmt.Errorf("failed to parse local ledger: %w", err)
+		}
+	}
+
+	if l.NFTs == nil {
+		l.NFTs = make(map[string]*LocalNFT)
+	}
+	if l.FTs == nil {
+		l.FTs = make(map[string]map[string]int32)
+	}
+	return l, nil
+}
+
+// localFile encodes the ledger as its file in the contract project
+func (l *ledger) localFile(contractDir string) (fileWrite, error) {
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fileWrite{}, fmt.Errorf("failed to marshal local ledger: %w", err)
+	}
+	return fileWrite{path: filepath.Join(LocalDir(contractDir), ledgerFile), content: content}, nil
+}
+
+// fileWrite is a file to replace by writeFilesAtomic, or to remove
+type fileWrite struct {
+	path    string
+	content []byte
+	remove  bool
+}
+
+// writeFileAtomic writes the file through a temporary file renamed over it,
+// so that an interrupted write never leaves a truncated file
+func writeFileAtomic(path string, content []byte) error {
+	return writeFilesAtomic([]fileWrite{{path: path, content: content}})
+}
+
+// renameFile renames the temporary files over the files, tests replace it to
+// fail a rename
+var renameFile = os.Rename
+
+// writeFilesAtomic writes the files through temporary files, and only
+// renames them over the files, and removes the files to remove, once all of
+// them are written. Each file is replaced atomically, and the files already
+// replaced are written back with their previous content when a later one
+// fails, so that a failed write leaves every file as it was unless the
+// process is interrupted
+func writeFilesAtomic(writes []fileWrite) error {
+	tmpNames := make([]string, len(writes))
+	defer func() {
+		for _, tmpName := range tmpNames {
+			if tmpName != "" {
+				os.Remove(tmpName)
+			}
+		}
+	}()
+
+	previous := make([]fileWrite, len(writes))
+	for i, write := range writes {
+		content, err := os.ReadFile(write.path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			previous[i] = fileWrite{path: write.path, remove: true}
+		case err != nil:
+			return fmt.Errorf("failed to read %s: %w", write.path, err)
+		default:
+			previous[i] = fileWrite{path: write.path, content: content}
+		}
+
+		if write.remove {
+			continue
+		}
+		tmpName, err := utils.WriteTempFile(write.path, write.content, 0644)
+		if err != nil {
+			return err
+		}
+		tmpNames[i] = tmpName
+	}
+
+	for i, write := range writes {
+		if err := replaceFile(write, tmpNames[i]); err != nil {
+			if rollbackErr := writeFilesAtomic(previous[:i]); rollbackErr != nil {
+				return fmt.Errorf("%w, and the files already replaced could not be restored: %w", err, rollbackErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceFile renames the temporary file over the file to write, or removes
+// the file to remove
+func replaceFile(write fileWrite, tmpName string) error {
+	if write.remove {
+		if err := os.Remove(write.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", write.path, err)
+		}
+		return nil
+	}
+	if err := renameFile(tmpName, write.path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", write.path, err)
+	}
+	return nil
+}
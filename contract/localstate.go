@@ -0,0 +1,147 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const (
+	// localStateFile holds the local state of the contract
+	localStateFile = "state.json"
+	// snapshotsDir holds the snapshots of the local state, one directory each
+	snapshotsDir = "snapshots"
+)
+
+// snapshotNamePattern restricts snapshot names to safe directory names
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// localFiles are the files of the local directory making up the local
+// state, saved by snapshots
+var localFiles = []string{localStateFile, ledgerFile}
+
+// LocalState is the state of the contract kept in the contract project
+// between local executions, as a JSON object
+type LocalState map[string]json.RawMessage
+
+// ReadLocalState reads the local state of the contract project. Until a local
+// execution updates it, the initial state deployed with the contract,
+// artifacts/state.json, is used if present
+func ReadLocalState(contractDir string) (LocalState, error) {
+	content, err := os.ReadFile(filepath.Join(LocalDir(contractDir), localStateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return readInitialState(contractDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local state: %w", err)
+	}
+	return parseLocalState(content)
+}
+
+// readInitialState reads the initial state deployed with the contract,
+// artifacts/state.json. Without initial state, the state is empty
+func readInitialState(contractDir string) (LocalState, error) {
+	content, err := os.ReadFile(filepath.Join(ArtifactsDir(contractDir), "state.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return LocalState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read initial state: %w", err)
+	}
+	return parseLocalState(content)
+}
+
+// parseLocalState parses a state file, which must hold a JSON object
+func parseLocalState(content []byte) (LocalState, error) {
+	var state LocalState
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse local state, it must be a JSON object: %w", err)
+	}
+	if state == nil {
+		state = LocalState{}
+	}
+	return state, nil
+}
+
+// localFile encodes the local state as its file in the contract project
+func (s LocalState) localFile(contractDir string) (fileWrite, error) {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fileWrite{}, fmt.Errorf("failed to marshal local state: %w", err)
+	}
+	return fileWrite{path: filepath.Join(LocalDir(contractDir), localStateFile), content: content}, nil
+}
+
+// ResetLocalState discards the local state and ledger of the contract
+// project, so that the next local execution starts from the initial state
+func ResetLocalState(contractDir string) error {
+	for _, file := range localFiles {
+		if err := os.Remove(filepath.Join(LocalDir(contractDir), file)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to reset local state: %w", err)
+		}
+	}
+	return nil
+}
+
+// SnapshotLocalState saves the local state and ledger of the contract
+// project under name, replacing any snapshot of the same name
+func SnapshotLocalState(contractDir string, name string) error {
+	snapshotDir, err := snapshotPath(contractDir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(snapshotDir); err != nil {
+		return fmt.Errorf("failed to replace snapshot %s: %w", name, err)
+	}
+	if err := os.MkdirAll(snapshotDir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot %s: %w", name, err)
+	}
+
+	return copyLocalFiles(LocalDir(contractDir), snapshotDir)
+}
+
+// RestoreLocalState replaces the local state and ledger of the contract
+// project by the ones saved by a snapshot. Files missing from the snapshot
+// are removed, and the files already replaced are put back if a file cannot
+// be copied
+func RestoreLocalState(contractDir string, name string) error {
+	snapshotDir, err := snapshotPath(contractDir, name)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(snapshotDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: %s", ErrSnapshotNotFound, name)
+	}
+
+	return copyLocalFiles(snapshotDir, LocalDir(contractDir))
+}
+
+// snapshotPath returns the directory of a snapshot
+func snapshotPath(contractDir string, name string) (string, error) {
+	if !snapshotNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: %q must start with a letter or digit and contain only letters, digits, '.', '_' and '-'", ErrInvalidSnapshot, name)
+	}
+	return filepath.Join(LocalDir(contractDir), snapshotsDir, name), nil
+}
+
+// copyLocalFiles replaces the local state files of dstDir by the ones of
+// srcDir, removing the ones srcDir does not have. Each file is replaced
+// atomically, and a failed copy puts back the files already replaced
+func copyLocalFiles(srcDir string, dstDir string) error {
+	var writes []fileWrite
+	for _, file := range localFiles {
+		content, err := os.ReadFile(filepath.Join(srcDir, file))
+		if errors.Is(err, os.ErrNotExist) {
+			writes = append(writes, fileWrite{path: filepath.Join(dstDir, file), remove: true})
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		writes = append(writes, fileWrite{path: filepath.Join(dstDir, file), content: content})
+	}
+	return writeFilesAtomic(writes)
+}
@@ -0,0 +1,134 @@
+package contract
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(LocalDir(dir), localStateFile)
+	ledgerPath := filepath.Join(LocalDir(dir), ledgerFile)
+	writeTestFile(t, statePath, `{"count": 1}`)
+
+	if err := SnapshotLocalState(dir, "one"); err != nil {
+		t.Fatalf("SnapshotLocalState() error = %v", err)
+	}
+	writeTestFile(t, statePath, `{"count": 2}`)
+	writeTestFile(t, ledgerPath, `{"nfts": {}}`)
+
+	if err := RestoreLocalState(dir, "one"); err != nil {
+		t.Fatalf("RestoreLocalState() error = %v", err)
+	}
+	if content, _ := os.ReadFile(statePath); string(content) != `{"count": 1}` {
+		t.Errorf("restored state = %s", content)
+	}
+	if _, err := os.Stat(ledgerPath); !os.IsNotExist(err) {
+		t.Errorf("ledger missing from the snapshot not removed: %v", err)
+	}
+}
+
+func TestRestoreLocalStateFailureLeavesStateUntouched(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(LocalDir(dir), localStateFile)
+	ledgerPath := filepath.Join(LocalDir(dir), ledgerFile)
+	writeTestFile(t, statePath, `{"count": 1}`)
+	writeTestFile(t, ledgerPath, `{"nfts": {}}`)
+	if err := SnapshotLocalState(dir, "one"); err != nil {
+		t.Fatalf("SnapshotLocalState() error = %v", err)
+	}
+	writeTestFile(t, statePath, `{"count": 2}`)
+
+	// The ledger of the snapshot cannot be read
+	snapshotLedger := filepath.Join(LocalDir(dir), snapshotsDir, "one", ledgerFile)
+	if err := os.Remove(snapshotLedger); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(snapshotLedger, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreLocalState(dir, "one"); err == nil {
+		t.Fatal("RestoreLocalState() succeeded with an unreadable snapshot")
+	}
+	if content, _ := os.ReadFile(statePath); string(content) != `{"count": 2}` {
+		t.Errorf("state after a failed restore = %s", content)
+	}
+	if content, _ := os.ReadFile(ledgerPath); string(content) != `{"nfts": {}}` {
+		t.Errorf("ledger after a failed restore = %s", content)
+	}
+}
+
+func TestWriteFilesAtomic(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	writeTestFile(t, first, "old")
+	writeTestFile(t, filepath.Join(dir, "file"), "")
+
+	// The second file cannot be written under a regular file
+	err := writeFilesAtomic([]fileWrite{
+		{path: first, content: []byte("new")},
+		{path: filepath.Join(dir, "file", "second.json"), content: []byte("new")},
+	})
+	if err == nil {
+		t.Fatal("writeFilesAtomic() succeeded")
+	}
+	if content, _ := os.ReadFile(first); string(content) != "old" {
+		t.Errorf("first file = %s after a failed write, want it untouched", content)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	if err := writeFilesAtomic([]fileWrite{{path: first, content: []byte("new")}}); err != nil {
+		t.Fatalf("writeFilesAtomic() error = %v", err)
+	}
+	info, err := os.Stat(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(first); string(content) != "new" || info.Mode().Perm() != 0644 {
+		t.Errorf("first file = %s with mode %o, want new with mode 644", content, info.Mode().Perm())
+	}
+}
+
+func TestWriteFilesAtomicRollsBackOnFailedRename(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+	removed := filepath.Join(dir, "removed.json")
+	writeTestFile(t, first, "old")
+	writeTestFile(t, second, "old")
+	writeTestFile(t, removed, "old")
+
+	// The rename of the second file fails once the first file is replaced
+	// and the removed one is removed
+	renames := 0
+	renameFile = func(oldpath, newpath string) error {
+		if renames++; renames == 2 {
+			return errors.New("rename failed")
+		}
+		return os.Rename(oldpath, newpath)
+	}
+	defer func() { renameFile = os.Rename }()
+
+	err := writeFilesAtomic([]fileWrite{
+		{path: first, content: []byte("new")},
+		{path: removed, remove: true},
+		{path: second, content: []byte("new")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "rename failed") {
+		t.Fatalf("writeFilesAtomic() error = %v, want the failed rename", err)
+	}
+	for _, path := range []string{first, second, removed} {
+		if content, _ := os.ReadFile(path); string(content) != "old" {
+			t.Errorf("%s = %q after a failed rename, want it restored", filepath.Base(path), content)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
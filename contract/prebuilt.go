@@ -0,0 +1,67 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// wasmMagic is the magic number every WASM binary starts with
+var wasmMagic = []byte("\x00asm")
+
+// Prebuilt are the files of a contract built beforehand, deployed as they
+// are instead of building the contract project
+type Prebuilt struct {
+	// WasmPath is the path of the WASM binary
+	WasmPath string
+	// SourcePath is the path of the lib.rs source of the contract
+	SourcePath string
+	// StatePath is the path of the JSON initial state of the contract
+	StatePath string
+}
+
+// validate checks the prebuilt files and makes their paths absolute, as
+// they are read by the deployer node
+func (p *Prebuilt) validate() error {
+	paths := []*string{&p.WasmPath, &p.SourcePath, &p.StatePath}
+	for _, path := range paths {
+		if *path == "" {
+			return fmt.Errorf("%w: the WASM binary, source and state files are all required", ErrInvalidArtifact)
+		}
+		absPath, err := filepath.Abs(*path)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArtifact, err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArtifact, err)
+		}
+		if info.IsDir() || info.Size() == 0 {
+			return fmt.Errorf("%w: %s is not a regular non-empty file", ErrInvalidArtifact, absPath)
+		}
+		*path = absPath
+	}
+
+	file, err := os.Open(p.WasmPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArtifact, err)
+	}
+	defer file.Close()
+	magic := make([]byte, len(wasmMagic))
+	if _, err := io.ReadFull(file, magic); err != nil || !bytes.Equal(magic, wasmMagic) {
+		return fmt.Errorf("%w: %s is not a WASM binary", ErrInvalidArtifact, p.WasmPath)
+	}
+
+	state, err := os.ReadFile(p.StatePath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArtifact, err)
+	}
+	if !json.Valid(state) {
+		return fmt.Errorf("%w: %s is not a JSON file", ErrInvalidArtifact, p.StatePath)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package contract
+
+import "time"
+
+// stageTimer notifies the stage callback and records the duration of each stage
+type stageTimer struct {
+	onStage StageCallback
+	current DeploymentStage
+	started time.Time
+	timings []StageTiming
+}
+
+func newStageTimer(onStage StageCallback) *stageTimer {
+	return &stageTimer{onStage: onStage}
+}
+
+// start ends the running stage, if any, and begins the given one
+func (t *stageTimer) start(stage DeploymentStage) {
+	t.stop()
+	t.current = stage
+	t.started = time.Now()
+	if t.onStage != nil {
+		t.onStage(stage)
+	}
+}
+
+// stop ends the running stage
+func (t *stageTimer) stop() {
+	if t.started.IsZero() {
+		return
+	}
+	t.timings = append(t.timings, StageTiming{Stage: t.current, Duration: time.Since(t.started)})
+	t.started = time.Time{}
+}
@@ -0,0 +1,102 @@
+package contract
+
+import (
+	"context"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+// ReplayStatus is the outcome of replaying a block of a token chain
+type ReplayStatus string
+
+const (
+	// ReplayOK is set when the local WASM module accepted the message of the block
+	ReplayOK ReplayStatus = "ok"
+	// ReplayDiverged is set when the local WASM module failed on the message
+	// of the block, which the node accepted
+	ReplayDiverged ReplayStatus = "diverged"
+	// ReplaySkipped is set when the block records no contract message, like
+	// the deployment block
+	ReplaySkipped ReplayStatus = "skipped"
+)
+
+// ReplayedBlock is a block of a token chain replayed locally
+type ReplayedBlock struct {
+	BlockNo        uint64       `json:"block_no"`
+	BlockID        string       `json:"block_id"`
+	Status         ReplayStatus `json:"status"`
+	ContractResult string       `json:"contract_result,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// SyncResult is the state of a contract reconstructed by replaying its token chain
+type SyncResult struct {
+	ContractHash string           `json:"contract_hash"`
+	Blocks       []*ReplayedBlock `json:"blocks"`
+	Diverged     int              `json:"diverged"`
+	State        LocalState       `json:"state"`
+	// Saved is set when the reconstructed state replaced the local state
+	Saved bool `json:"saved"`
+}
+
+// Sync fetches the token chain of a contract and replays the message of each
+// block, oldest first, through the local WASM module of the contract project,
+// starting from the initial state. If save is set and no block diverged, the
+// reconstructed state and ledger replace the local ones
+func Sync(ctx context.Context, cfg *config.Config, contractHash string, contractDir string, save bool) (*SyncResult, error) {
+	// Fail before fetching the blocks if the artifact cannot be replayed
+	if _, err := ResolveArtifact(contractDir); err != nil {
+		return nil, err
+	}
+
+	blocks, err := History(ctx, cfg, contractHash, false)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := newHostEnv(contractDir, cfg.HostFunctions)
+	if err != nil {
+		return nil, err
+	}
+	env.ledger = &ledger{NFTs: make(map[string]*LocalNFT), FTs: make(map[string]map[string]int32)}
+	if env.state, err = readInitialState(contractDir); err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{ContractHash: contractHash}
+	for _, block := range blocks {
+		replayed := &ReplayedBlock{BlockNo: block.BlockNo, BlockID: block.BlockID}
+		result.Blocks = append(result.Blocks, replayed)
+
+		if len(block.Data) == 0 || block.Data[0] != '{' {
+			replayed.Status = ReplaySkipped
+			continue
+		}
+
+		// Replay on a copy, so that a diverging block leaves no partial update
+		next, err := env.clone()
+		if err != nil {
+			return nil, err
+		}
+		contractResult, err := callWasm(contractDir, string(block.Data), next)
+		if err != nil {
+			replayed.Status = ReplayDiverged
+			replayed.Error = err.Error()
+			result.Diverged++
+			continue
+		}
+		replayed.Status = ReplayOK
+		replayed.ContractResult = contractResult
+		env = next
+	}
+	result.State = env.state
+
+	if save && result.Diverged == 0 {
+		if err := env.write(true, true); err != nil {
+			return nil, err
+		}
+		result.Saved = true
+	}
+
+	return result, nil
+}
@@ -1,10 +1,19 @@
 package contract
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // DeploymentResult represents the result of a contract deployment
 type DeploymentResult struct {
-	ContractHash string
-	Success      bool
-	Message      string
+	ContractHash string        `json:"contract_hash"`
+	Success      bool          `json:"success"`
+	Message      string        `json:"message"`
+	Stages       []StageTiming `json:"stages"`
+	// Warning reports that the deployment succeeded but could not be
+	// recorded in the contract project
+	Warning string `json:"warning,omitempty"`
 }
 
 // DeploymentStage represents a stage in the deployment process
@@ -16,39 +25,72 @@ const (
 	StageDeploy
 )
 
+func (s DeploymentStage) String() string {
+	switch s {
+	case StageBuild:
+		return "build"
+	case StageGenerate:
+		return "generate"
+	case StageDeploy:
+		return "deploy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the stage as its name
+func (s DeploymentStage) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 // StageCallback is a function that gets called when a stage begins
 type StageCallback func(stage DeploymentStage)
 
-// ExecutionResult represents the result of a contract execution
-type ExecutionResult struct {
-	Success bool
-	Message string
-	ContractResult string
+// StageTiming records how long a deployment stage took
+type StageTiming struct {
+	Stage    DeploymentStage
+	Duration time.Duration
 }
 
-type SmartContractResult struct {
-	Id          string `json:"id"`
-	Mode        int    `json:"mode"`
-	Hash        string `json:"string"`
-	OnlyPrivKey bool   `json:"only_priv_key"`
+// MarshalJSON encodes the timing with the duration in milliseconds
+func (t StageTiming) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Stage      DeploymentStage `json:"stage"`
+		DurationMs int64           `json:"duration_ms"`
+	}{
+		Stage:      t.Stage,
+		DurationMs: t.Duration.Milliseconds(),
+	})
 }
 
-// SmartContractAPIResponseV1 represents the standard API response structure
-type SmartContractAPIResponseV1 struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Result  string `json:"result"`
+// ExecutionResult represents the result of a contract execution
+type ExecutionResult struct {
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	ContractResult string `json:"contract_result"`
+	DryRun         bool   `json:"dry_run"`
+	// Preflight is the result of the local execution run before submitting
+	// the execution, if any
+	Preflight *PreflightResult `json:"preflight,omitempty"`
+	// RequestID is the ID of the execution request issued by the node
+	RequestID string `json:"request_id,omitempty"`
 }
 
-// SmartContractAPIResponseV1 represents the standard API response structure
-type SmartContractAPIResponseV2 struct {
-	Status  bool                `json:"status"`
-	Message string              `json:"message"`
-	Result  SmartContractResult `json:"result"`
-}
+// PreflightStatus is the outcome of a preflight execution
+type PreflightStatus string
+
+const (
+	PreflightPassed PreflightStatus = "passed"
+	PreflightFailed PreflightStatus = "failed"
+	// PreflightInconclusive is reported when the contract calls host functions
+	// disabled in the configuration
+	PreflightInconclusive PreflightStatus = "inconclusive"
+)
 
-type SmartContractBlock struct {
-	BlockNo           string `json:"BlockNo"`
-	BlockId           string `json:"BlockId"`
-	SmartContractData string `json:"SmartContractData"`
+// PreflightResult represents the result of the local execution of a message
+// before it is submitted
+type PreflightResult struct {
+	Status         PreflightStatus `json:"status"`
+	ContractResult string          `json:"contract_result,omitempty"`
+	Error          string          `json:"error,omitempty"`
 }
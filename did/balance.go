@@ -0,0 +1,90 @@
+package did
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+)
+
+// Balance holds the RBT balances of a DID
+type Balance struct {
+	DID string `json:"did"`
+	// Available is the RBT amount the DID can spend
+	Available float64 `json:"available_rbt"`
+	Locked    float64 `json:"locked_rbt"`
+	Pledged   float64 `json:"pledged_rbt"`
+	Pinned    float64 `json:"pinned_rbt"`
+}
+
+// Token is an RBT token held by a DID
+type Token struct {
+	Token  string      `json:"token"`
+	Status TokenStatus `json:"status"`
+}
+
+// TokenStatus is the status of a token in the DID wallet, as numbered by the node
+type TokenStatus int
+
+const (
+	TokenFree TokenStatus = iota
+	TokenLocked
+	TokenPledged
+	TokenTransferred
+)
+
+func (s TokenStatus) String() string {
+	switch s {
+	case TokenFree:
+		return "free"
+	case TokenLocked:
+		return "locked"
+	case TokenPledged:
+		return "pledged"
+	case TokenTransferred:
+		return "transferred"
+	default:
+		return fmt.Sprintf("status %d", int(s))
+	}
+}
+
+// MarshalText encodes the status as its name
+func (s TokenStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// GetBalance returns the RBT balances of the DID
+func GetBalance(ctx context.Context, cfg *config.Config, did string) (*Balance, error) {
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
+
+	accountInfo, err := client.GetAccountInfo(ctx, did)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balance{
+		DID:       did,
+		Available: accountInfo.RBTAmount,
+		Locked:    accountInfo.LockedRBT,
+		Pledged:   accountInfo.PledgedRBT,
+		Pinned:    accountInfo.PinnedRBT,
+	}, nil
+}
+
+// ListTokens returns the RBT tokens held by the DID
+func ListTokens(ctx context.Context, cfg *config.Config, did string) ([]*Token, error) {
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
+
+	tokenInfo, err := client.GetAllTokens(ctx, did, "rbt")
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*Token, 0, len(tokenInfo))
+	for _, info := range tokenInfo {
+		tokens = append(tokens, &Token{Token: info.Token, Status: TokenStatus(info.Status)})
+	}
+
+	return tokens, nil
+}
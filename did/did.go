@@ -1,196 +1,75 @@
 package did
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
-	"net/url"
+	"path/filepath"
 
 	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
 )
 
-func CreateDID(homeDir string, isLocalnet bool) (string, error) {
-	cfg, err := config.LoadConfig(homeDir)
-	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
-	}
-
-	requestURL, err := url.JoinPath(cfg.Network.DeployerNodeURL, "/api/createdid")
-	if err != nil {
-		return "", fmt.Errorf("failed to join URL: %v", err)
-	}
-
-	// Create a new multipart writer
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	didConfig := map[string]interface{}{
-		"Type":          4,
-		"priv_pwd":      "mypassword",
-		"mnemonic_file": "",
-		"childPath":     0,
-	}
-	didConfigBytes, err := json.Marshal(didConfig)
-	if err != nil {
-		return "", fmt.Errorf("failed to encode didConfig: %v", err)
-	}
-
-	err = writer.WriteField("did_config", string(didConfigBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to write didConfig field: %v", err)
-	}
-
-	// Close the writer
-	err = writer.Close()
-	if err != nil {
-		return "", fmt.Errorf("failed to close writer: %v", err)
-	}
-
-	// Create a POST request
-	req, err := http.NewRequest("POST", requestURL, body)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
+// CreateDID creates a DID on the deployer node and registers it on the network.
+// password protects the private key of the DID and signs its registration. If
+// the test RBT generation of a localnet DID fails, the created DID is returned
+// along with the error
+func CreateDID(ctx context.Context, cfg *config.Config, password string, opts CreateOptions) (*CreateResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	// Make the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to perform request: %v", err)
+	mnemonicFile := opts.MnemonicFile
+	if mnemonicFile != "" {
+		absPath, err := filepath.Abs(mnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid mnemonic file path: %w", ErrInvalidOptions, err)
+		}
+		mnemonicFile = absPath
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
 
-	// Check if the response status is not 200
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(responseBody))
-	}
-	// Parse the JSON response
-	var response createDidResponse
-	err = json.Unmarshal(responseBody, &response)
+	createDidResult, err := client.CreateDID(ctx, &rubixapi.DIDConfig{
+		Type:         int(opts.Type),
+		PrivPWD:      password,
+		MnemonicFile: mnemonicFile,
+		ChildPath:    opts.ChildPath,
+		MasterDID:    opts.MasterDID,
+	})
 	if err != nil {
-		return "", fmt.Errorf("createDID: failed to parse response: %v", err)
+		return nil, err
 	}
 
-	// Check if the API call was successful
-	if !response.Status {
-		return "", fmt.Errorf("API error: %s", response.Message)
+	registerDidErr := registerDID(ctx, client, createDidResult.DID, password)
+	if registerDidErr != nil {
+		return nil, fmt.Errorf("failed to register DID: %w", registerDidErr)
 	}
 
-	registerDidErr := registerDID(cfg.Network.DeployerNodeURL, response.Result.DID)
-	if registerDidErr != nil {
-		return "", fmt.Errorf("failed to register DID: %v", registerDidErr)
+	result := &CreateResult{
+		DID:    createDidResult.DID,
+		PeerID: createDidResult.PeerID,
+		Type:   opts.Type,
 	}
 
-	if isLocalnet {
-		errGenerateTestRBT := GenerateOneTestRBT(cfg.Network.DeployerNodeURL, response.Result.DID)
+	if opts.Localnet {
+		_, errGenerateTestRBT := generateTestRBT(ctx, client, result.DID, password, opts.TestTokens)
 		if errGenerateTestRBT != nil {
-			return "", fmt.Errorf("failed to generate test RBT: %v", errGenerateTestRBT)
+			// The DID is registered, the caller must still record it
+			return result, fmt.Errorf("DID %s created but failed to generate test RBT: %w", result.DID, errGenerateTestRBT)
 		}
 	}
 
-	// Return the DID from the response
-	return response.Result.DID, nil
+	return result, nil
 }
 
-func registerDID(baseURL string, did string) error {
-	requestURL, err := url.JoinPath(baseURL, "/api/register-did")
+func registerDID(ctx context.Context, client *rubixapi.Client, did string, password string) error {
+	requestId, err := client.RegisterDID(ctx, did)
 	if err != nil {
-		return fmt.Errorf("failed to join URL: %v", err)
-	}
-
-	requestBody := map[string]interface{}{
-		"did": did,
+		return err
 	}
 
-	requestBodyBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(requestBodyBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to perform request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(responseBody))
-	}
-
-	var registerDidResp registerDidResponse
-	if err = json.Unmarshal(responseBody, &registerDidResp); err != nil {
-		return fmt.Errorf("registerDID: failed to unmarshal JSON: %v", err)
-	}
-
-	if !registerDidResp.Status {
-		return fmt.Errorf("failed to Register DID, error: %s", registerDidResp.Message)
-	}
-
-	requestId := registerDidResp.Result.Id
-
-	if err = signatureResponse(baseURL, requestId); err != nil {
-		return fmt.Errorf("failed to send signature response: %v", err)
-	}
-
-	return nil
-}
-
-func signatureResponse(baseURL, requestId string) error {
-	data := map[string]interface{}{
-		"id":       requestId,
-		"mode":     0,
-		"password": "mypassword",
-	}
-
-	bodyJSON, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("error marshaling JSON, err: %v", err)
-	}
-
-	requestURL, err := url.JoinPath(baseURL, "/api/signature-response")
-	if err != nil {
-		return fmt.Errorf("failed to join URL: %v", err)
-	}
-
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(bodyJSON))
-	if err != nil {
-		return fmt.Errorf("error creating HTTP request, err: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
-
-	client := &http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("error sending HTTP request, err: %v", err)
-	}
-	defer resp.Body.Close()
-
-	_, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("error reading response body: %s\n", err)
+	if _, err = client.SignatureResponse(ctx, requestId, password); err != nil {
+		return fmt.Errorf("%w: %w", ErrSignature, err)
 	}
 
 	return nil
@@ -0,0 +1,14 @@
+package did
+
+import "errors"
+
+var (
+	// ErrSignature is returned when the node fails to sign a DID or token request
+	ErrSignature = errors.New("failed to send signature response")
+	// ErrInvalidOptions is returned when DID creation options are not accepted by the node
+	ErrInvalidOptions = errors.New("invalid DID options")
+	// ErrNotRegistered is returned when a DID or alias is not in the local registry
+	ErrNotRegistered = errors.New("DID not found in the local registry")
+	// ErrInvalidAlias is returned when a DID alias is malformed or already in use
+	ErrInvalidAlias = errors.New("invalid DID alias")
+)
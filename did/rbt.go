@@ -1,69 +1,51 @@
 package did
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
 )
 
-func GenerateOneTestRBT(baseURL string, did string) (error) {
-	requestURL, err := url.JoinPath(baseURL, "/api/generate-test-token")
-	if err != nil {
-		return fmt.Errorf("generate test token: unable to form request URL")
-	}
+// FaucetResult represents the result of a test RBT generation
+type FaucetResult struct {
+	DID     string `json:"did"`
+	Tokens  int    `json:"tokens"`
+	Message string `json:"message"`
+}
 
-	tokenRequest := rbtGenerateRequest{
-		DID:            did,
-		NumberOfTokens: 1,
-	}
+// GenerateTestRBT generates test RBT tokens for the DID. It is only supported
+// on localnet. password is the private key password of the DID
+func GenerateTestRBT(ctx context.Context, cfg *config.Config, did string, password string, numberOfTokens int) (*FaucetResult, error) {
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
 
-	// Marshal the struct to JSON
-	jsonData, err := json.Marshal(tokenRequest)
+	message, err := generateTestRBT(ctx, client, did, password, numberOfTokens)
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
+		return nil, err
 	}
 
-	// Create a POST request
-	req, err := http.NewRequest("POST", requestURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
+	return &FaucetResult{
+		DID:     did,
+		Tokens:  numberOfTokens,
+		Message: message,
+	}, nil
+}
 
-	// Make the HTTP request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to perform request: %v", err)
+func generateTestRBT(ctx context.Context, client *rubixapi.Client, did string, password string, numberOfTokens int) (string, error) {
+	if numberOfTokens < 1 {
+		return "", fmt.Errorf("%w: number of tokens must be at least 1", ErrInvalidOptions)
 	}
-	defer resp.Body.Close()
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
+	id, err := client.GenerateTestToken(ctx, did, numberOfTokens)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
+		return "", err
 	}
 
-	var response map[string]interface{}
-	err3 := json.Unmarshal(body, &response)
-	if err3 != nil {
-		fmt.Println("Error unmarshaling response:", err3)
-	}
-
-	result := response["result"].(map[string]interface{})
-	id := result["id"].(string)
-
-	signRespErr := signatureResponse(baseURL, id)
-	if signRespErr != nil {
-		return fmt.Errorf("failed to sign response: %v", signRespErr)
+	message, err := client.SignatureResponse(ctx, id, password)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSignature, err)
 	}
 
-	return signRespErr
+	return message, nil
 }
@@ -0,0 +1,153 @@
+package did
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/rubixchain/rubix-nexus/config"
+)
+
+var aliasRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Record is a DID created with Rubix Nexus
+type Record struct {
+	DID       string    `json:"did"`
+	PeerID    string    `json:"peer_id"`
+	Type      Type      `json:"type"`
+	Network   string    `json:"network"`
+	CreatedAt time.Time `json:"created_at"`
+	Alias     string    `json:"alias,omitempty"`
+}
+
+// Registry is the local record of the DIDs created with Rubix Nexus, kept
+// in the Rubix Nexus directory under the home directory
+type Registry struct {
+	path    string
+	records []*Record
+}
+
+// RegistryPath returns the path of the registry file under the home directory
+func RegistryPath(homeDir string) string {
+	return filepath.Join(config.Dir(homeDir), "dids.json")
+}
+
+// OpenRegistry reads the registry of the home directory. An empty registry
+// is returned if the registry file does not exist yet
+func OpenRegistry(homeDir string) (*Registry, error) {
+	r := &Registry{path: RegistryPath(homeDir)}
+
+	content, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DID registry: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &r.records); err != nil {
+		return nil, fmt.Errorf("failed to parse DID registry: %w", err)
+	}
+
+	return r, nil
+}
+
+// List returns the recorded DIDs, sorted by creation time
+func (r *Registry) List() []*Record {
+	records := append([]*Record(nil), r.records...)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	return records
+}
+
+// Find returns the record of a DID, looked up by DID or alias
+func (r *Registry) Find(didOrAlias string) (*Record, error) {
+	for _, record := range r.records {
+		if record.DID == didOrAlias || (record.Alias != "" && record.Alias == didOrAlias) {
+			return record, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNotRegistered, didOrAlias)
+}
+
+// Resolve returns the DID of an alias. Values that are not aliases are
+// returned as is, as they may be DIDs not created with Rubix Nexus
+func (r *Registry) Resolve(didOrAlias string) string {
+	for _, record := range r.records {
+		if record.Alias != "" && record.Alias == didOrAlias {
+			return record.DID
+		}
+	}
+	return didOrAlias
+}
+
+// SetAlias sets the alias of a recorded DID, then writes the registry
+func (r *Registry) SetAlias(didOrAlias string, alias string) error {
+	record, err := r.Find(didOrAlias)
+	if err != nil {
+		return err
+	}
+	if err := r.CheckAlias(alias, record.DID); err != nil {
+		return err
+	}
+
+	record.Alias = alias
+	return r.save()
+}
+
+// CheckAlias checks that alias is valid and not used by another DID than did
+func (r *Registry) CheckAlias(alias string, did string) error {
+	if !aliasRegex.MatchString(alias) {
+		return fmt.Errorf("%w: %q: must start with a letter and contain only alphanumeric characters, hyphens and underscores", ErrInvalidAlias, alias)
+	}
+	for _, record := range r.records {
+		if record.DID == did {
+			continue
+		}
+		if record.Alias == alias || record.DID == alias {
+			return fmt.Errorf("%w: %q is already used by %s", ErrInvalidAlias, alias, record.DID)
+		}
+	}
+	return nil
+}
+
+// Add records a DID, replacing any previous record of it, then writes the registry
+func (r *Registry) Add(record *Record) error {
+	if record.Alias != "" {
+		if err := r.CheckAlias(record.Alias, record.DID); err != nil {
+			return err
+		}
+	}
+
+	for i, existing := range r.records {
+		if existing.DID == record.DID {
+			r.records[i] = record
+			return r.save()
+		}
+	}
+
+	r.records = append(r.records, record)
+	return r.save()
+}
+
+// save writes the registry file
+func (r *Registry) save() error {
+	content, err := json.MarshalIndent(r.records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode DID registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create DID registry directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write DID registry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/rubixapi"
+)
+
+// trnxIDRegex extracts the transaction ID from the message of a completed transfer
+var trnxIDRegex = regexp.MustCompile(`trnxid\s+(\S+)`)
+
+// Quorum types of the node
+const (
+	QuorumPublic  = 1
+	QuorumPrivate = 2
+)
+
+// TransferRequest is an RBT transfer between two DIDs
+type TransferRequest struct {
+	From       string
+	To         string
+	Amount     float64
+	Comment    string
+	QuorumType int
+}
+
+// TransferResult represents the result of an RBT transfer
+type TransferResult struct {
+	TransactionID string  `json:"transaction_id"`
+	From          string  `json:"from"`
+	To            string  `json:"to"`
+	Amount        float64 `json:"amount"`
+	Message       string  `json:"message"`
+	// Warning is set when the transfer completed but its transaction ID
+	// could not be read from the message of the node
+	Warning string `json:"warning,omitempty"`
+}
+
+// Validate checks the transfer request before it is sent to the node
+func (r *TransferRequest) Validate() error {
+	if r.From == "" || r.To == "" {
+		return fmt.Errorf("%w: sender and receiver DIDs are required", ErrInvalidOptions)
+	}
+	if r.From == r.To {
+		return fmt.Errorf("%w: sender and receiver DIDs must differ", ErrInvalidOptions)
+	}
+	if r.Amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidOptions)
+	}
+	if r.QuorumType != QuorumPublic && r.QuorumType != QuorumPrivate {
+		return fmt.Errorf("%w: quorum type must be %d (public) or %d (private)", ErrInvalidOptions, QuorumPublic, QuorumPrivate)
+	}
+	return nil
+}
+
+// TransferRBT transfers RBT between two DIDs. password is the private key
+// password of the sender
+func TransferRBT(ctx context.Context, cfg *config.Config, request *TransferRequest, password string) (*TransferResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	client := rubixapi.NewClient(cfg.Network.DeployerNodeURL)
+
+	requestID, err := client.InitiateRBTTransfer(ctx, &rubixapi.RBTTransferRequest{
+		Receiver:   request.To,
+		Sender:     request.From,
+		TokenCount: request.Amount,
+		Comment:    request.Comment,
+		Type:       request.QuorumType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := client.SignatureResponse(ctx, requestID, password)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignature, err)
+	}
+
+	result := &TransferResult{
+		From:    request.From,
+		To:      request.To,
+		Amount:  request.Amount,
+		Message: message,
+	}
+	if result.TransactionID = transactionID(message); result.TransactionID == "" {
+		result.Warning = fmt.Sprintf("transaction ID not found in the node response %q", message)
+	}
+
+	return result, nil
+}
+
+// transactionID extracts the transaction ID from the message of a completed
+// transfer, or returns an empty string
+func transactionID(message string) string {
+	if match := trnxIDRegex.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package did
+
+import "testing"
+
+func TestTransactionID(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			// Message of the signature response of a completed transfer
+			name:    "node response",
+			message: "Transfer finished successfully in 2.753918458s with trnxid 6b0d51d4ad8d6c1d9c2b3ea29b8c8f6a4fa2e8d1ec0e6e4c9f1f3c4a8a5b9e21",
+			want:    "6b0d51d4ad8d6c1d9c2b3ea29b8c8f6a4fa2e8d1ec0e6e4c9f1f3c4a8a5b9e21",
+		},
+		{
+			name:    "trailing text",
+			message: "Transfer finished successfully in 1s with trnxid abc123 \n",
+			want:    "abc123",
+		},
+		{
+			name:    "no transaction ID",
+			message: "Transfer finished successfully",
+		},
+		{
+			name:    "empty message",
+			message: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transactionID(tt.message); got != tt.want {
+				t.Errorf("transactionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,31 +1,100 @@
 package did
 
-type createDidResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Result  struct {
-		DID    string `json:"did"`
-		PeerID string `json:"peer_id"`
-	} `json:"result"`
+import (
+	"fmt"
+	"strings"
+)
+
+// CreateResult represents the result of a DID creation
+type CreateResult struct {
+	DID    string `json:"did"`
+	PeerID string `json:"peer_id"`
+	Type   Type   `json:"type"`
+}
+
+// Type is the DID type, as numbered by the node
+type Type int
+
+const (
+	TypeBasic Type = iota
+	TypeStandard
+	TypeWallet
+	TypeChild
+	TypeLite
+)
+
+var typeNames = []string{"basic", "standard", "wallet", "child", "lite"}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return "unknown"
+	}
+	return typeNames[t]
+}
+
+// MarshalText encodes the type as its name
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a type name
+func (t *Type) UnmarshalText(text []byte) error {
+	parsed, err := ParseType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
 }
 
-type registerDidResponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Result  struct {
-		Id string `json:"id"`
-		Mode int `json:"mode"`
-		OnlyPrivKey bool `json:"only_priv_key"`
+// ParseType returns the type with the given name
+func ParseType(name string) (Type, error) {
+	for i, typeName := range typeNames {
+		if strings.EqualFold(name, typeName) {
+			return Type(i), nil
+		}
 	}
+	return 0, fmt.Errorf("%w: unknown DID type %q: must be one of %s", ErrInvalidOptions, name, strings.Join(typeNames, ", "))
 }
 
-type singatureResReponse struct {
-	Status  bool   `json:"status"`
-	Message string `json:"message"`
-	Result  string `json:"result"`
+// CreateOptions are the options of a DID creation
+type CreateOptions struct {
+	Type Type
+	// MnemonicFile is the path of a mnemonic file to recover a lite DID from.
+	// The file is read by the node
+	MnemonicFile string
+	// ChildPath is the derivation index of a lite DID, to derive several
+	// DIDs from the same mnemonic
+	ChildPath int
+	// MasterDID is the DID a child DID is created under
+	MasterDID string
+	// Localnet generates TestTokens test RBT tokens for the DID
+	Localnet   bool
+	TestTokens int
 }
 
-type rbtGenerateRequest struct {
-	NumberOfTokens int    `json:"number_of_tokens"`
-	DID            string `json:"did"`
-}
\ No newline at end of file
+// Validate checks the combinations of options accepted by the node
+func (o *CreateOptions) Validate() error {
+	if o.Type < TypeBasic || o.Type > TypeLite {
+		return fmt.Errorf("%w: unknown DID type %d", ErrInvalidOptions, o.Type)
+	}
+	if o.Type != TypeLite && o.MnemonicFile != "" {
+		return fmt.Errorf("%w: a mnemonic file can only be used with %s DIDs", ErrInvalidOptions, TypeLite)
+	}
+	if o.Type != TypeLite && o.ChildPath != 0 {
+		return fmt.Errorf("%w: a child path can only be used with %s DIDs", ErrInvalidOptions, TypeLite)
+	}
+	if o.ChildPath < 0 {
+		return fmt.Errorf("%w: child path must not be negative", ErrInvalidOptions)
+	}
+	if o.Type == TypeChild && o.MasterDID == "" {
+		return fmt.Errorf("%w: a master DID is required for %s DIDs", ErrInvalidOptions, TypeChild)
+	}
+	if o.Type != TypeChild && o.MasterDID != "" {
+		return fmt.Errorf("%w: a master DID can only be used with %s DIDs", ErrInvalidOptions, TypeChild)
+	}
+	if o.Localnet && o.TestTokens < 1 {
+		return fmt.Errorf("%w: number of test tokens must be at least 1", ErrInvalidOptions)
+	}
+	return nil
+}
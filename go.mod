@@ -3,16 +3,21 @@ module github.com/rubixchain/rubix-nexus
 go 1.22
 
 require (
+	github.com/bytecodealliance/wasmtime-go v1.0.0
 	github.com/pelletier/go-toml/v2 v2.2.3
 	github.com/rubixchain/rubix-wasm/go-wasm-bridge v0.1.2
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/crypto v0.31.0
+	golang.org/x/term v0.27.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/bytecodealliance/wasmtime-go v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
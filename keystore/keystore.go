@@ -0,0 +1,222 @@
+// Package keystore implements an encrypted local store of DID private key
+// passwords, kept in the Rubix Nexus directory under the home directory
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/utils"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+
+	// scrypt parameters used to derive the encryption key from the passphrase
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+var (
+	// ErrNotFound is returned when the keystore has no password for a DID
+	ErrNotFound = errors.New("no password stored for DID")
+	// ErrWrongPassphrase is returned when the keystore passphrase does not decrypt its entries
+	ErrWrongPassphrase = errors.New("wrong keystore passphrase")
+)
+
+// Keystore holds the DID passwords, each one encrypted with AES-GCM using a
+// key derived from the keystore passphrase
+type Keystore struct {
+	path string
+	file keystoreFile
+}
+
+type keystoreFile struct {
+	Version int               `json:"version"`
+	KDF     kdfParams         `json:"kdf"`
+	Entries map[string]*entry `json:"entries"`
+}
+
+type kdfParams struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+type entry struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Path returns the path of the keystore file under the home directory
+func Path(homeDir string) string {
+	return filepath.Join(config.Dir(homeDir), "keystore.json")
+}
+
+// Open reads the keystore of the home directory. An empty keystore is
+// returned if the keystore file does not exist yet
+func Open(homeDir string) (*Keystore, error) {
+	k := &Keystore{
+		path: Path(homeDir),
+		file: keystoreFile{
+			Version: keystoreVersion,
+			Entries: make(map[string]*entry),
+		},
+	}
+
+	content, err := os.ReadFile(k.path)
+	if os.IsNotExist(err) {
+		return k, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	if err := json.Unmarshal(content, &k.file); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore: %w", err)
+	}
+	if k.file.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d", k.file.Version)
+	}
+	if k.file.Entries == nil {
+		k.file.Entries = make(map[string]*entry)
+	}
+
+	return k, nil
+}
+
+// Has reports whether the keystore holds a password for the DID
+func (k *Keystore) Has(did string) bool {
+	_, ok := k.file.Entries[did]
+	return ok
+}
+
+// DIDs returns the DIDs the keystore holds passwords for, sorted
+func (k *Keystore) DIDs() []string {
+	dids := make([]string, 0, len(k.file.Entries))
+	for did := range k.file.Entries {
+		dids = append(dids, did)
+	}
+	sort.Strings(dids)
+	return dids
+}
+
+// Get decrypts the password of the DID
+func (k *Keystore) Get(did string, passphrase string) (string, error) {
+	e, ok := k.file.Entries[did]
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrNotFound, did)
+	}
+
+	aead, err := k.aead(passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := aead.Open(nil, e.Nonce, e.Ciphertext, []byte(did))
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+
+	return string(password), nil
+}
+
+// Put encrypts and stores the password of the DID, then writes the keystore.
+// The passphrase must match the one of the passwords already stored
+func (k *Keystore) Put(did string, password string, passphrase string) error {
+	if len(k.file.Entries) == 0 {
+		salt := make([]byte, saltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+		k.file.KDF = kdfParams{Name: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt}
+	} else if err := k.verifyPassphrase(passphrase); err != nil {
+		return err
+	}
+
+	aead, err := k.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	k.file.Entries[did] = &entry{
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, []byte(password), []byte(did)),
+	}
+
+	return k.save()
+}
+
+// Remove deletes the password of the DID, then writes the keystore
+func (k *Keystore) Remove(did string) error {
+	if !k.Has(did) {
+		return fmt.Errorf("%w %s", ErrNotFound, did)
+	}
+	delete(k.file.Entries, did)
+
+	return k.save()
+}
+
+// verifyPassphrase checks the passphrase against any stored password
+func (k *Keystore) verifyPassphrase(passphrase string) error {
+	for did := range k.file.Entries {
+		_, err := k.Get(did, passphrase)
+		return err
+	}
+	return nil
+}
+
+// aead derives the encryption key from the passphrase
+func (k *Keystore) aead(passphrase string) (cipher.AEAD, error) {
+	kdf := k.file.KDF
+	if kdf.Name != "scrypt" {
+		return nil, fmt.Errorf("unsupported keystore key derivation function %q", kdf.Name)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), kdf.Salt, kdf.N, kdf.R, kdf.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive keystore key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keystore cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// save writes the keystore file, readable by the current user only. The
+// file is replaced atomically, so that a failed write never loses the
+// stored passwords
+func (k *Keystore) save() error {
+	content, err := json.MarshalIndent(k.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(k.path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPutGetRoundTrip(t *testing.T) {
+	homeDir := t.TempDir()
+
+	store, err := Open(homeDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Put("bafydid1", "password1", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Put("bafydid2", "password2", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	info, err := os.Stat(Path(homeDir))
+	if err != nil {
+		t.Fatalf("keystore file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("keystore file mode = %o, want 600", perm)
+	}
+
+	// Read the passwords back from the file
+	reopened, err := Open(homeDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for did, want := range map[string]string{"bafydid1": "password1", "bafydid2": "password2"} {
+		got, err := reopened.Get(did, "passphrase")
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", did, err)
+		}
+		if got != want {
+			t.Errorf("Get(%s) = %q, want %q", did, got, want)
+		}
+	}
+	if dids := reopened.DIDs(); len(dids) != 2 || dids[0] != "bafydid1" || dids[1] != "bafydid2" {
+		t.Errorf("DIDs() = %v", dids)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Put("bafydid1", "password1", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, err := store.Get("bafydid1", "other"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Get() with wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if err := store.Put("bafydid2", "password2", "other"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Put() with wrong passphrase error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if store.Has("bafydid2") {
+		t.Error("password stored with a wrong passphrase")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Put("bafydid1", "password1", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if _, err := store.Get("bafydid2", "passphrase"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Remove("bafydid1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := store.Remove("bafydid1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Remove() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestCiphertextBoundToDID(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Put("bafydid1", "password1", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	// An entry moved to another DID must not decrypt
+	store.file.Entries["bafydid2"] = store.file.Entries["bafydid1"]
+	if _, err := store.Get("bafydid2", "passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Get() of a moved entry error = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
+
+func TestSaveRestrictsExistingFile(t *testing.T) {
+	homeDir := t.TempDir()
+	store, err := Open(homeDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := store.Put("bafydid1", "password1", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// A keystore file left readable by others, for instance by an older version
+	if err := os.Chmod(Path(homeDir), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put("bafydid2", "password2", "passphrase"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	info, err := os.Stat(Path(homeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("keystore file mode = %o, want 600", perm)
+	}
+	entries, err := os.ReadDir(filepath.Dir(Path(homeDir)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
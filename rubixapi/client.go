@@ -0,0 +1,89 @@
+// Package rubixapi implements a typed client for the Rubix node HTTP API
+package rubixapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client is a Rubix node API client
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the node running at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+	}
+}
+
+// BaseURL returns the URL of the node the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// postJSON sends requestBody as JSON to the endpoint and decodes the response into out
+func (c *Client) postJSON(ctx context.Context, endpoint string, requestBody interface{}, out interface{}) error {
+	bodyBytes, err := json.Marshal(requestBody)
+	if err != nil {
+		return &Error{Endpoint: endpoint, Err: fmt.Errorf("failed to marshal request body: %w", err)}
+	}
+
+	return c.do(ctx, http.MethodPost, endpoint, "application/json", bytes.NewBuffer(bodyBytes), out)
+}
+
+// getJSON sends a GET request with the query parameters to the endpoint and
+// decodes the response into out
+func (c *Client) getJSON(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, endpoint+"?"+query.Encode(), "", nil, out)
+}
+
+// do sends a request to the endpoint, which may carry a query string, and
+// decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method string, endpoint string, contentType string, body io.Reader, out interface{}) error {
+	endpointPath, rawQuery, _ := strings.Cut(endpoint, "?")
+	requestURL, err := url.JoinPath(c.baseURL, endpointPath)
+	if err != nil {
+		return &Error{Endpoint: endpoint, Err: fmt.Errorf("unable to form request URL: %w", err)}
+	}
+	if rawQuery != "" {
+		requestURL += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return &Error{Endpoint: endpoint, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &Error{Endpoint: endpoint, Err: fmt.Errorf("%w: failed to send request: %w", ErrNodeUnreachable, err)}
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Error{Endpoint: endpoint, StatusCode: resp.StatusCode, Err: fmt.Errorf("%w: failed to read response: %w", ErrNodeUnreachable, err)}
+	}
+
+	if err := json.Unmarshal(responseBody, out); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &Error{Endpoint: endpoint, StatusCode: resp.StatusCode, Message: string(responseBody)}
+		}
+		return &Error{Endpoint: endpoint, StatusCode: resp.StatusCode, Err: fmt.Errorf("failed to parse response: %w", err)}
+	}
+
+	return nil
+}
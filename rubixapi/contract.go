@@ -0,0 +1,133 @@
+package rubixapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Smart contract endpoints of the node API
+const (
+	EndpointGenerateSmartContract       = "/api/generate-smart-contract"
+	EndpointDeploySmartContract         = "/api/deploy-smart-contract"
+	EndpointExecuteSmartContract        = "/api/execute-smart-contract"
+	EndpointSmartContractTokenChainData = "/api/get-smart-contract-token-chain-data"
+)
+
+// GenerateSmartContract uploads the contract binary, source and state files
+// and returns the hash of the generated smart contract token
+func (c *Client) GenerateSmartContract(ctx context.Context, request *GenerateSmartContractRequest) (string, error) {
+	// Create a buffer to store the multipart form data
+	var requestBody bytes.Buffer
+	writer := multipart.NewWriter(&requestBody)
+
+	if err := writer.WriteField("did", request.DeployerDID); err != nil {
+		return "", &Error{Endpoint: EndpointGenerateSmartContract, Err: fmt.Errorf("failed to add did field: %w", err)}
+	}
+
+	formFiles := []struct {
+		field string
+		path  string
+	}{
+		{field: "binaryCodePath", path: request.WasmPath},
+		{field: "rawCodePath", path: request.LibPath},
+		{field: "schemaFilePath", path: request.StatePath},
+	}
+	for _, formFile := range formFiles {
+		if err := addFormFile(writer, formFile.field, formFile.path); err != nil {
+			return "", &Error{Endpoint: EndpointGenerateSmartContract, Err: err}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", &Error{Endpoint: EndpointGenerateSmartContract, Err: fmt.Errorf("failed to close multipart writer: %w", err)}
+	}
+
+	var apiResp SmartContractAPIResponseV1
+	if err := c.do(ctx, http.MethodPost, EndpointGenerateSmartContract, writer.FormDataContentType(), &requestBody, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointGenerateSmartContract, apiResp.Message)
+	}
+
+	return apiResp.Result, nil
+}
+
+// DeploySmartContract requests the deployment of a generated smart contract
+// and returns the ID of the signature request issued by the node
+func (c *Client) DeploySmartContract(ctx context.Context, request *DeploySmartContractRequest) (string, error) {
+	var apiResp SmartContractAPIResponseV2
+	if err := c.postJSON(ctx, EndpointDeploySmartContract, request, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointDeploySmartContract, apiResp.Message)
+	}
+
+	return apiResp.Result.Id, nil
+}
+
+// ExecuteSmartContract requests the execution of a deployed smart contract
+// and returns the ID of the signature request issued by the node
+func (c *Client) ExecuteSmartContract(ctx context.Context, request *ExecuteSmartContractRequest) (string, error) {
+	var apiResp SmartContractAPIResponseV2
+	if err := c.postJSON(ctx, EndpointExecuteSmartContract, request, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointExecuteSmartContract, apiResp.Message)
+	}
+
+	return apiResp.Result.Id, nil
+}
+
+// GetSmartContractTokenChainData returns the blocks of a smart contract token chain.
+// If onlyLatest is set, only the latest block is returned
+func (c *Client) GetSmartContractTokenChainData(ctx context.Context, contractHash string, onlyLatest bool) ([]*SmartContractBlock, error) {
+	requestBody := struct {
+		Latest bool   `json:"latest"`
+		Token  string `json:"token"`
+	}{
+		Latest: onlyLatest,
+		Token:  contractHash,
+	}
+
+	var apiResp SmartContractDataResponse
+	if err := c.postJSON(ctx, EndpointSmartContractTokenChainData, requestBody, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Status {
+		return nil, rejected(EndpointSmartContractTokenChainData, apiResp.Message)
+	}
+
+	if len(apiResp.SmartContractBlocks) == 0 {
+		return nil, rejected(EndpointSmartContractTokenChainData, fmt.Sprintf("unable to fetch blocks for smart contract token : %v", contractHash))
+	}
+
+	return apiResp.SmartContractBlocks, nil
+}
+
+// addFormFile copies the file at path into a new form file field
+func addFormFile(writer *multipart.Writer, field string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(field, filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to create %s form file: %w", filepath.Base(path), err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package rubixapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// DID and token endpoints of the node API
+const (
+	EndpointCreateDID         = "/api/createdid"
+	EndpointRegisterDID       = "/api/register-did"
+	EndpointGenerateTestToken = "/api/generate-test-token"
+	EndpointSignatureResponse = "/api/signature-response"
+	EndpointAccountInfo       = "/api/get-account-info"
+	EndpointAllTokens         = "/api/get-all-tokens"
+	EndpointInitiateTransfer  = "/api/initiate-rbt-transfer"
+)
+
+// CreateDID creates a new DID on the node
+func (c *Client) CreateDID(ctx context.Context, didConfig *DIDConfig) (*CreateDIDResult, error) {
+	didConfigBytes, err := json.Marshal(didConfig)
+	if err != nil {
+		return nil, &Error{Endpoint: EndpointCreateDID, Err: fmt.Errorf("failed to encode didConfig: %w", err)}
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("did_config", string(didConfigBytes)); err != nil {
+		return nil, &Error{Endpoint: EndpointCreateDID, Err: fmt.Errorf("failed to write didConfig field: %w", err)}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, &Error{Endpoint: EndpointCreateDID, Err: fmt.Errorf("failed to close writer: %w", err)}
+	}
+
+	var apiResp CreateDIDResponse
+	if err := c.do(ctx, http.MethodPost, EndpointCreateDID, writer.FormDataContentType(), body, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Status {
+		return nil, rejected(EndpointCreateDID, apiResp.Message)
+	}
+
+	return &apiResp.Result, nil
+}
+
+// RegisterDID publishes the DID to the network and returns the ID of the
+// signature request issued by the node
+func (c *Client) RegisterDID(ctx context.Context, did string) (string, error) {
+	requestBody := struct {
+		DID string `json:"did"`
+	}{
+		DID: did,
+	}
+
+	var apiResp SmartContractAPIResponseV2
+	if err := c.postJSON(ctx, EndpointRegisterDID, requestBody, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointRegisterDID, apiResp.Message)
+	}
+
+	return apiResp.Result.Id, nil
+}
+
+// GenerateTestToken generates test RBT tokens for the DID and returns the ID
+// of the signature request issued by the node. It is only supported on localnet
+func (c *Client) GenerateTestToken(ctx context.Context, did string, numberOfTokens int) (string, error) {
+	requestBody := &GenerateTestTokenRequest{
+		DID:            did,
+		NumberOfTokens: numberOfTokens,
+	}
+
+	var apiResp SmartContractAPIResponseV2
+	if err := c.postJSON(ctx, EndpointGenerateTestToken, requestBody, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointGenerateTestToken, apiResp.Message)
+	}
+
+	return apiResp.Result.Id, nil
+}
+
+// GetAccountInfo returns the RBT balances of the DID
+func (c *Client) GetAccountInfo(ctx context.Context, did string) (*AccountInfo, error) {
+	var apiResp AccountInfoResponse
+	if err := c.getJSON(ctx, EndpointAccountInfo, url.Values{"did": {did}}, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Status {
+		return nil, rejected(EndpointAccountInfo, apiResp.Message)
+	}
+	if len(apiResp.AccountInfo) == 0 {
+		return nil, rejected(EndpointAccountInfo, fmt.Sprintf("no account info for DID %s", did))
+	}
+
+	return &apiResp.AccountInfo[0], nil
+}
+
+// GetAllTokens returns the tokens of the given type held by the DID, such as "rbt"
+func (c *Client) GetAllTokens(ctx context.Context, did string, tokenType string) ([]TokenInfo, error) {
+	var apiResp TokensResponse
+	if err := c.getJSON(ctx, EndpointAllTokens, url.Values{"did": {did}, "type": {tokenType}}, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Status {
+		return nil, rejected(EndpointAllTokens, apiResp.Message)
+	}
+
+	return apiResp.TokenInfo, nil
+}
+
+// InitiateRBTTransfer requests an RBT transfer and returns the ID of the
+// signature request issued by the node
+func (c *Client) InitiateRBTTransfer(ctx context.Context, request *RBTTransferRequest) (string, error) {
+	var apiResp SmartContractAPIResponseV2
+	if err := c.postJSON(ctx, EndpointInitiateTransfer, request, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointInitiateTransfer, apiResp.Message)
+	}
+
+	return apiResp.Result.Id, nil
+}
+
+// SignatureResponse signs the pending request with the given password and
+// returns the message reported by the node once the request is completed
+func (c *Client) SignatureResponse(ctx context.Context, requestID string, password string) (string, error) {
+	requestBody := struct {
+		Id       string `json:"id"`
+		Mode     int    `json:"mode"`
+		Password string `json:"password"`
+	}{
+		Id:       requestID,
+		Mode:     0,
+		Password: password,
+	}
+
+	var apiResp SignatureResponseReply
+	if err := c.postJSON(ctx, EndpointSignatureResponse, requestBody, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointSignatureResponse, apiResp.Message)
+	}
+
+	return apiResp.Message, nil
+}
@@ -0,0 +1,50 @@
+package rubixapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNodeUnreachable is matched by errors of requests that never got a response from the node
+	ErrNodeUnreachable = errors.New("rubix node unreachable")
+	// ErrNodeRejected is matched by errors of requests that the node responded to with a failure
+	ErrNodeRejected = errors.New("rubix node rejected request")
+)
+
+// Error is returned by every Client method when a node call fails
+type Error struct {
+	// Endpoint is the API path that was called
+	Endpoint string
+	// StatusCode is the HTTP status code of the response, if one was received
+	StatusCode int
+	// Message is the error message reported by the node
+	Message string
+	// Err is the underlying error, if the failure happened before the node could respond
+	Err error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", e.Endpoint, e.Err)
+	case e.StatusCode != 0 && e.StatusCode != 200:
+		return fmt.Sprintf("%s: unexpected status code: %d, response: %s", e.Endpoint, e.StatusCode, e.Message)
+	default:
+		return fmt.Sprintf("%s: %s", e.Endpoint, e.Message)
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is ErrNodeRejected. ErrNodeUnreachable is matched through Err
+func (e *Error) Is(target error) bool {
+	return target == ErrNodeRejected && e.Err == nil
+}
+
+// rejected returns the Error for a response whose status field is false
+func rejected(endpoint string, message string) *Error {
+	return &Error{Endpoint: endpoint, StatusCode: 200, Message: message}
+}
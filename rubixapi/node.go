@@ -0,0 +1,76 @@
+package rubixapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Node endpoints of the node API
+const (
+	EndpointNodeStatus = "/api/node-status"
+	EndpointPeerID     = "/api/get-peer-id"
+)
+
+// NodeStatusResponse represents the response of the node status API
+type NodeStatusResponse struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Version string `json:"version"`
+}
+
+// NodeStatus returns the status reported by the node
+func (c *Client) NodeStatus(ctx context.Context) (*NodeStatusResponse, error) {
+	var apiResp NodeStatusResponse
+	if err := c.do(ctx, http.MethodGet, EndpointNodeStatus, "", nil, &apiResp); err != nil {
+		return nil, err
+	}
+	if !apiResp.Status {
+		return nil, rejected(EndpointNodeStatus, apiResp.Message)
+	}
+
+	return &apiResp, nil
+}
+
+// GetPeerID returns the peer ID of the node
+func (c *Client) GetPeerID(ctx context.Context) (string, error) {
+	var apiResp SmartContractAPIResponseV1
+	if err := c.do(ctx, http.MethodGet, EndpointPeerID, "", nil, &apiResp); err != nil {
+		return "", err
+	}
+	if !apiResp.Status {
+		return "", rejected(EndpointPeerID, apiResp.Message)
+	}
+
+	return apiResp.Result, nil
+}
+
+// ProbeRoute checks that the node routes the endpoint without calling it.
+// A GET request without body, which carries nothing the node could act on,
+// is sent to the endpoint, and any response but 404 means the route exists.
+// It returns the HTTP status code of the response
+func (c *Client) ProbeRoute(ctx context.Context, endpoint string) (int, error) {
+	requestURL, err := url.JoinPath(c.baseURL, endpoint)
+	if err != nil {
+		return 0, &Error{Endpoint: endpoint, Err: fmt.Errorf("unable to form request URL: %w", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return 0, &Error{Endpoint: endpoint, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, &Error{Endpoint: endpoint, Err: fmt.Errorf("%w: failed to send request: %w", ErrNodeUnreachable, err)}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, &Error{Endpoint: endpoint, StatusCode: resp.StatusCode, Message: "endpoint not served by the node"}
+	}
+	return resp.StatusCode, nil
+}
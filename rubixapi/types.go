@@ -0,0 +1,142 @@
+package rubixapi
+
+import "encoding/json"
+
+// SmartContractAPIResponseV1 represents the standard API response structure
+type SmartContractAPIResponseV1 struct {
+	Status  bool   `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"`
+}
+
+// SmartContractAPIResponseV2 represents the API response structure of
+// endpoints that return a signature request
+type SmartContractAPIResponseV2 struct {
+	Status  bool                `json:"status"`
+	Message string              `json:"message"`
+	Result  SmartContractResult `json:"result"`
+}
+
+// SmartContractResult represents a signature request issued by the node
+type SmartContractResult struct {
+	Id          string `json:"id"`
+	Mode        int    `json:"mode"`
+	Hash        string `json:"string"`
+	OnlyPrivKey bool   `json:"only_priv_key"`
+}
+
+// SignatureResponseReply represents the response of the signature response API.
+// The shape of Result depends on the operation that was signed
+type SignatureResponseReply struct {
+	Status  bool            `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// SmartContractDataResponse represents the response of the smart contract token chain data API
+type SmartContractDataResponse struct {
+	Status              bool                  `json:"status"`
+	Message             string                `json:"message"`
+	SmartContractBlocks []*SmartContractBlock `json:"SCDataReply"`
+}
+
+// SmartContractBlock represents a block of a smart contract token chain.
+// BlockNo is decoded from either a JSON number or a numeric string
+type SmartContractBlock struct {
+	BlockNo           json.Number `json:"BlockNo"`
+	BlockId           string      `json:"BlockId"`
+	SmartContractData string      `json:"SmartContractData"`
+}
+
+// GenerateSmartContractRequest holds the files uploaded to generate a smart contract
+type GenerateSmartContractRequest struct {
+	DeployerDID string
+	WasmPath    string
+	LibPath     string
+	StatePath   string
+}
+
+// DeploySmartContractRequest represents the request body of the deploy smart contract API
+type DeploySmartContractRequest struct {
+	Comment            string  `json:"comment"`
+	DeployerAddr       string  `json:"deployerAddr"`
+	QuorumType         int     `json:"quorumType"`
+	RbtAmount          float64 `json:"rbtAmount"`
+	SmartContractToken string  `json:"smartContractToken"`
+}
+
+// ExecuteSmartContractRequest represents the request body of the execute smart contract API
+type ExecuteSmartContractRequest struct {
+	Comment            string `json:"comment"`
+	ExecutorAddr       string `json:"executorAddr"`
+	QuorumType         int    `json:"quorumType"`
+	SmartContractData  string `json:"smartContractData"`
+	SmartContractToken string `json:"smartContractToken"`
+}
+
+// DIDConfig represents the DID configuration sent to the create DID API
+type DIDConfig struct {
+	Type         int    `json:"Type"`
+	PrivPWD      string `json:"priv_pwd"`
+	MnemonicFile string `json:"mnemonic_file"`
+	ChildPath    int    `json:"childPath"`
+	MasterDID    string `json:"master_did,omitempty"`
+}
+
+// CreateDIDResponse represents the response of the create DID API
+type CreateDIDResponse struct {
+	Status  bool            `json:"status"`
+	Message string          `json:"message"`
+	Result  CreateDIDResult `json:"result"`
+}
+
+// CreateDIDResult holds the DID created by the node
+type CreateDIDResult struct {
+	DID    string `json:"did"`
+	PeerID string `json:"peer_id"`
+}
+
+// RBTTransferRequest represents the request body of the initiate RBT transfer API
+type RBTTransferRequest struct {
+	Receiver   string  `json:"receiver"`
+	Sender     string  `json:"sender"`
+	TokenCount float64 `json:"tokenCOunt"`
+	Comment    string  `json:"comment"`
+	Type       int     `json:"type"`
+}
+
+// AccountInfoResponse represents the response of the account info API
+type AccountInfoResponse struct {
+	Status      bool          `json:"status"`
+	Message     string        `json:"message"`
+	AccountInfo []AccountInfo `json:"account_info"`
+}
+
+// AccountInfo holds the RBT balances of a DID
+type AccountInfo struct {
+	DID        string  `json:"did"`
+	DIDType    int     `json:"did_type"`
+	RBTAmount  float64 `json:"rbt_amount"`
+	PledgedRBT float64 `json:"pledged_rbt"`
+	LockedRBT  float64 `json:"locked_rbt"`
+	PinnedRBT  float64 `json:"pinned_rbt"`
+}
+
+// TokensResponse represents the response of the all tokens API
+type TokensResponse struct {
+	Status    bool        `json:"status"`
+	Message   string      `json:"message"`
+	TokenInfo []TokenInfo `json:"token_info"`
+}
+
+// TokenInfo holds a token of a DID and its status in the DID wallet
+type TokenInfo struct {
+	Token  string `json:"token"`
+	Status int    `json:"token_status"`
+}
+
+// GenerateTestTokenRequest represents the request body of the generate test token API
+type GenerateTestTokenRequest struct {
+	NumberOfTokens int    `json:"number_of_tokens"`
+	DID            string `json:"did"`
+}
@@ -0,0 +1,11 @@
+package scenario
+
+import "errors"
+
+var (
+	// ErrInvalidScenario is returned when a scenario file cannot be parsed or
+	// refers to undefined DIDs or variables
+	ErrInvalidScenario = errors.New("invalid scenario")
+	// ErrAssertion is returned when the result or error of a step is not the expected one
+	ErrAssertion = errors.New("scenario assertion failed")
+)
@@ -0,0 +1,429 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rubixchain/rubix-nexus/config"
+	"github.com/rubixchain/rubix-nexus/contract"
+	"github.com/rubixchain/rubix-nexus/did"
+)
+
+// PasswordFunc returns the private key password of a DID. newDID is set,
+// and did empty, when the password protects a DID about to be created
+type PasswordFunc func(did string, newDID bool) (string, error)
+
+// Options are the dependencies of a scenario run
+type Options struct {
+	// Registry records the created DIDs and resolves DID aliases
+	Registry *did.Registry
+	// Password is called for the DIDs whose password the scenario does not set
+	Password PasswordFunc
+	// OnProgress is called with a message before each DID creation and step
+	OnProgress func(message string)
+}
+
+// StepResult is the outcome of a step
+type StepResult struct {
+	Name     string        `json:"name"`
+	Action   string        `json:"action"`
+	Passed   bool          `json:"passed"`
+	Duration time.Duration `json:"-"`
+	// Result is the result of the deployment, execution or faucet request
+	Result interface{} `json:"result,omitempty"`
+	// Error is the error of the step, expected or not
+	Error string `json:"error,omitempty"`
+	// Failure explains why an assertion of the step failed
+	Failure  string            `json:"failure,omitempty"`
+	Captured map[string]string `json:"captured,omitempty"`
+}
+
+// MarshalJSON encodes the result with the duration in milliseconds
+func (r *StepResult) MarshalJSON() ([]byte, error) {
+	type stepResult StepResult
+	return json.Marshal(struct {
+		*stepResult
+		DurationMs int64 `json:"duration_ms"`
+	}{
+		stepResult: (*stepResult)(r),
+		DurationMs: r.Duration.Milliseconds(),
+	})
+}
+
+// Report is the outcome of a scenario run. The run stops at the first
+// failed step
+type Report struct {
+	Name string `json:"name"`
+	// DIDs maps the names of the DIDs of the scenario to their DID
+	DIDs     map[string]string `json:"dids"`
+	Steps    []*StepResult     `json:"steps"`
+	Passed   bool              `json:"passed"`
+	Duration time.Duration     `json:"-"`
+}
+
+// MarshalJSON encodes the report with the duration in milliseconds
+func (r *Report) MarshalJSON() ([]byte, error) {
+	type report Report
+	return json.Marshal(struct {
+		*report
+		DurationMs int64 `json:"duration_ms"`
+	}{
+		report:     (*report)(r),
+		DurationMs: r.Duration.Milliseconds(),
+	})
+}
+
+// runner holds the state of a running scenario
+type runner struct {
+	cfg      *config.Config
+	scenario *Scenario
+	opts     Options
+	vars     *variables
+	// passwords caches the passwords of the DIDs by DID
+	passwords map[string]string
+}
+
+// Run creates the DIDs of the scenario, then runs its steps in order until
+// one fails. The report is returned along with the error of the failed step,
+// wrapping ErrAssertion when the step did not have the expected outcome
+func Run(ctx context.Context, cfg *config.Config, scenario *Scenario, opts Options) (*Report, error) {
+	r := &runner{
+		cfg:       cfg,
+		scenario:  scenario,
+		opts:      opts,
+		vars:      &variables{vars: make(map[string]string), dids: make(map[string]string)},
+		passwords: make(map[string]string),
+	}
+	for name, value := range scenario.Vars {
+		r.vars.vars[name] = value
+	}
+
+	report := &Report{Name: scenario.Name, DIDs: r.vars.dids, Steps: []*StepResult{}}
+	start := time.Now()
+	defer func() {
+		report.Duration = time.Since(start)
+	}()
+
+	for _, spec := range scenario.DIDs {
+		if err := r.setupDID(ctx, spec); err != nil {
+			return report, err
+		}
+	}
+
+	for i, step := range scenario.Steps {
+		r.progress(fmt.Sprintf("[%d/%d] %s", i+1, len(scenario.Steps), step.Name))
+		result, err := r.runStep(ctx, step)
+		report.Steps = append(report.Steps, result)
+		if err != nil {
+			return report, err
+		}
+	}
+	report.Passed = true
+
+	return report, nil
+}
+
+// setupDID creates a DID of the scenario and records it in the registry, or
+// resolves it if it already exists
+func (r *runner) setupDID(ctx context.Context, spec *DIDSpec) error {
+	password := spec.Password
+	if err := r.vars.expandAll(&password); err != nil {
+		return fmt.Errorf("DID %s: %w", spec.Name, err)
+	}
+
+	if spec.DID != "" {
+		value, err := r.vars.expand(spec.DID)
+		if err != nil {
+			return fmt.Errorf("DID %s: %w", spec.Name, err)
+		}
+		r.vars.dids[spec.Name] = r.opts.Registry.Resolve(value)
+		if password != "" {
+			r.passwords[r.vars.dids[spec.Name]] = password
+		}
+		return nil
+	}
+
+	opts := did.CreateOptions{
+		Type:       did.TypeLite,
+		Localnet:   spec.Localnet,
+		TestTokens: spec.Tokens,
+	}
+	if spec.Type != "" {
+		opts.Type, _ = did.ParseType(spec.Type)
+	}
+	if opts.Localnet && opts.TestTokens == 0 {
+		opts.TestTokens = 1
+	}
+	if spec.MasterDID != "" {
+		masterDID, err := r.vars.expand(spec.MasterDID)
+		if err != nil {
+			return fmt.Errorf("DID %s: %w", spec.Name, err)
+		}
+		if opts.MasterDID, err = r.resolveDID(masterDID); err != nil {
+			return fmt.Errorf("DID %s: %w", spec.Name, err)
+		}
+	}
+
+	if password == "" {
+		var err error
+		if password, err = r.opts.Password("", true); err != nil {
+			return err
+		}
+	}
+
+	r.progress(fmt.Sprintf("Creating DID %s...", spec.Name))
+	result, createErr := did.CreateDID(ctx, r.cfg, password, opts)
+	if result == nil {
+		return fmt.Errorf("failed to create DID %s: %w", spec.Name, createErr)
+	}
+	r.vars.dids[spec.Name] = result.DID
+	r.passwords[result.DID] = password
+
+	err := r.opts.Registry.Add(&did.Record{
+		DID:       result.DID,
+		PeerID:    result.PeerID,
+		Type:      result.Type,
+		Network:   r.cfg.NetworkName,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("DID %s created but not recorded: %w", result.DID, err)
+	}
+	if createErr != nil {
+		return fmt.Errorf("failed to create DID %s: %w", spec.Name, createErr)
+	}
+	return nil
+}
+
+// runStep runs a step, captures its variables and checks its assertions
+func (r *runner) runStep(ctx context.Context, step *Step) (*StepResult, error) {
+	result := &StepResult{Name: step.Name, Action: step.action()}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	value, stepErr := r.do(ctx, step)
+	if stepErr != nil {
+		result.Error = stepErr.Error()
+	}
+	switch {
+	case errors.Is(stepErr, ErrInvalidScenario):
+		// An invalid step does not satisfy expect_error
+		return result, fmt.Errorf("step %s: %w", step.Name, stepErr)
+	case step.ExpectError != "":
+		expected, err := r.vars.expand(step.ExpectError)
+		if err != nil {
+			return result, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		if stepErr == nil {
+			result.Result = value
+			result.Failure = fmt.Sprintf("expected error %q, the step succeeded", expected)
+		} else if !strings.Contains(stepErr.Error(), expected) {
+			result.Failure = fmt.Sprintf("expected error %q", expected)
+		}
+		if result.Failure != "" {
+			return result, fmt.Errorf("%w: step %s: %s", ErrAssertion, step.Name, result.Failure)
+		}
+		result.Passed = true
+		return result, nil
+	case stepErr != nil:
+		return result, fmt.Errorf("step %s failed: %w", step.Name, stepErr)
+	}
+	result.Result = value
+
+	// Capture first, so that the assertions can refer to the captured variables
+	for _, name := range sortedKeys(step.Capture) {
+		captured, err := field(value, step.Capture[name])
+		if err != nil {
+			return result, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		if result.Captured == nil {
+			result.Captured = make(map[string]string)
+		}
+		result.Captured[name] = formatValue(captured)
+		r.vars.vars[name] = result.Captured[name]
+	}
+
+	for _, name := range sortedKeys(step.Expect) {
+		expected, err := r.vars.expandValue(step.Expect[name])
+		if err != nil {
+			return result, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		actual, err := field(value, name)
+		if err != nil {
+			return result, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		if !matches(expected, actual) {
+			result.Failure = fmt.Sprintf("%s: expected %s, got %s", name, formatValue(expected), formatValue(actual))
+			return result, fmt.Errorf("%w: step %s: %s", ErrAssertion, step.Name, result.Failure)
+		}
+	}
+	result.Passed = true
+
+	return result, nil
+}
+
+// do runs the action of a step and returns its result
+func (r *runner) do(ctx context.Context, step *Step) (interface{}, error) {
+	switch {
+	case step.Deploy != nil:
+		return r.deploy(ctx, *step.Deploy)
+	case step.Execute != nil:
+		return r.execute(ctx, *step.Execute)
+	default:
+		return r.faucet(ctx, *step.Faucet)
+	}
+}
+
+// deploy runs a deploy step. The step is a copy, expanded in place
+func (r *runner) deploy(ctx context.Context, step DeployStep) (*contract.DeploymentResult, error) {
+	if err := r.vars.expandAll(&step.ContractDir, &step.Wasm, &step.Source, &step.State, &step.Deployer); err != nil {
+		return nil, err
+	}
+
+	var prebuilt *contract.Prebuilt
+	if step.Wasm != "" {
+		prebuilt = &contract.Prebuilt{
+			WasmPath:   r.scenario.path(step.Wasm),
+			SourcePath: r.scenario.path(step.Source),
+			StatePath:  r.scenario.path(step.State),
+		}
+	}
+	amount := defaultDeployAmount
+	if step.Amount != nil {
+		amount = *step.Amount
+	}
+
+	deployerDid, password, err := r.signer(step.Deployer)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := contract.Deploy(ctx, r.cfg, r.scenario.path(step.ContractDir), prebuilt, deployerDid, amount, password, nil)
+	if err != nil {
+		return nil, fmt.Errorf("deployment failed: %w", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("deployment failed: %s", result.Message)
+	}
+	return result, nil
+}
+
+// execute runs an execute step. The step is a copy, expanded in place
+func (r *runner) execute(ctx context.Context, step ExecuteStep) (*contract.ExecutionResult, error) {
+	if err := r.vars.expandAll(&step.ContractDir, &step.ContractHash, &step.Executor, &step.MessageFile); err != nil {
+		return nil, err
+	}
+	contractDir := r.scenario.path(step.ContractDir)
+
+	if step.ContractHash == "" {
+		deployment, err := contract.LatestDeployment(contractDir, r.cfg.NetworkName)
+		if err != nil {
+			return nil, fmt.Errorf("contract hash is not set: %w", err)
+		}
+		if !step.SkipPreflight {
+			if err := deployment.VerifyArtifact(contractDir); err != nil {
+				return nil, err
+			}
+		}
+		step.ContractHash = deployment.ContractHash
+	}
+
+	executorDid, password, err := r.signer(step.Executor)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *contract.ExecutionResult
+	if step.MessageFile != "" {
+		result, err = contract.Execute(ctx, r.cfg, step.ContractHash, executorDid, password, contractDir, r.scenario.path(step.MessageFile), !step.SkipPreflight)
+	} else {
+		message, expandErr := r.vars.expandValue(step.Message)
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		content, marshalErr := json.Marshal(message)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("%w: %w", contract.ErrInvalidMessage, marshalErr)
+		}
+		result, err = contract.ExecuteMessage(ctx, r.cfg, step.ContractHash, executorDid, password, contractDir, string(content), !step.SkipPreflight)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("execution failed: %w", err)
+	}
+	return result, nil
+}
+
+// faucet runs a faucet step. The step is a copy, expanded in place
+func (r *runner) faucet(ctx context.Context, step FaucetStep) (*did.FaucetResult, error) {
+	if err := r.vars.expandAll(&step.DID); err != nil {
+		return nil, err
+	}
+
+	faucetDid, password, err := r.signer(step.DID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := did.GenerateTestRBT(ctx, r.cfg, faucetDid, password, step.Tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate test RBT: %w", err)
+	}
+	return result, nil
+}
+
+// signer resolves the DID signing a step and its password
+func (r *runner) signer(value string) (string, string, error) {
+	signerDid, err := r.resolveDID(value)
+	if err != nil {
+		return "", "", err
+	}
+
+	password, ok := r.passwords[signerDid]
+	if !ok {
+		if password, err = r.opts.Password(signerDid, false); err != nil {
+			return "", "", err
+		}
+		r.passwords[signerDid] = password
+	}
+	return signerDid, password, nil
+}
+
+// resolveDID returns the DID named by value, a DID of the scenario or a DID
+// or alias, or the default DID of the network if value is empty
+func (r *runner) resolveDID(value string) (string, error) {
+	if value == "" {
+		value = r.cfg.Network.DefaultDID
+	}
+	if value == "" {
+		return "", fmt.Errorf("%w: a DID is required, or set default_did of network %q", ErrInvalidScenario, r.cfg.NetworkName)
+	}
+	if scenarioDid, ok := r.vars.dids[value]; ok {
+		return scenarioDid, nil
+	}
+	return r.opts.Registry.Resolve(value), nil
+}
+
+// progress reports a progress message, if requested
+func (r *runner) progress(message string) {
+	if r.opts.OnProgress != nil {
+		r.opts.OnProgress(message)
+	}
+}
+
+// sortedKeys returns the keys of m in order, so that steps capture and
+// assert in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,253 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/rubixchain/rubix-nexus/did"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultDeployAmount is the RBT amount of a deploy step without amount
+const defaultDeployAmount = 0.001
+
+// namePattern restricts DID and variable names, so that they can be
+// referenced as ${name}
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Scenario is a workflow of deployments and executions, run in order
+// against the network of the configuration
+type Scenario struct {
+	Name string `yaml:"name"`
+	// Vars are the initial variables, referenced as ${name} in the steps
+	Vars map[string]string `yaml:"vars"`
+	// DIDs are created, or resolved, before the first step
+	DIDs  []*DIDSpec `yaml:"dids"`
+	Steps []*Step    `yaml:"steps"`
+
+	// dir is the directory of the scenario file, relative paths of the
+	// steps are resolved from it
+	dir string
+}
+
+// DIDSpec declares a DID of the scenario, referenced by name in the steps
+type DIDSpec struct {
+	Name string `yaml:"name"`
+	// DID is an existing DID, or alias, used instead of creating one
+	DID string `yaml:"did"`
+	// Type is the type of the created DID, lite by default
+	Type string `yaml:"type"`
+	// MasterDID is the master of a created child DID, a DID of the scenario
+	// declared before it, or a DID or alias
+	MasterDID string `yaml:"master_did"`
+	// Localnet generates Tokens test RBT tokens for the created DID
+	Localnet bool `yaml:"localnet"`
+	Tokens   int  `yaml:"tokens"`
+	// Password is the private key password of the DID. It defaults to the
+	// password resolved by the CLI
+	Password string `yaml:"password"`
+}
+
+// Step is a deploy, execute or faucet action, with the variables it
+// captures and the assertions on its result
+type Step struct {
+	Name    string       `yaml:"name"`
+	Deploy  *DeployStep  `yaml:"deploy"`
+	Execute *ExecuteStep `yaml:"execute"`
+	Faucet  *FaucetStep  `yaml:"faucet"`
+	// Capture maps variable names to fields of the result of the step,
+	// such as contract_hash, with nested fields separated by dots
+	Capture map[string]string `yaml:"capture"`
+	// Expect maps fields of the result of the step to their expected value
+	Expect map[string]interface{} `yaml:"expect"`
+	// ExpectError must be contained in the error of the step, which is
+	// then expected to fail
+	ExpectError string `yaml:"expect_error"`
+}
+
+// DeployStep deploys a contract project, or prebuilt files
+type DeployStep struct {
+	ContractDir string `yaml:"contract_dir"`
+	Wasm        string `yaml:"wasm"`
+	Source      string `yaml:"source"`
+	State       string `yaml:"state"`
+	// Deployer is a DID of the scenario, or a DID or alias. It defaults to
+	// default_did of the network
+	Deployer string   `yaml:"deployer"`
+	Amount   *float64 `yaml:"amount"`
+}
+
+// ExecuteStep executes a deployed contract with a message file or an
+// inline message
+type ExecuteStep struct {
+	ContractDir string `yaml:"contract_dir"`
+	// ContractHash defaults to the latest deployment of the contract project
+	ContractHash string `yaml:"contract_hash"`
+	// Executor is a DID of the scenario, or a DID or alias. It defaults to
+	// default_did of the network
+	Executor      string                 `yaml:"executor"`
+	Message       map[string]interface{} `yaml:"message"`
+	MessageFile   string                 `yaml:"message_file"`
+	SkipPreflight bool                   `yaml:"skip_preflight"`
+}
+
+// FaucetStep generates test RBT tokens on localnet
+type FaucetStep struct {
+	DID    string `yaml:"did"`
+	Tokens int    `yaml:"tokens"`
+}
+
+// Load reads and checks a scenario file
+func Load(path string) (*Scenario, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidScenario, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario.dir = filepath.Dir(absPath)
+	if scenario.Name == "" {
+		scenario.Name = filepath.Base(path)
+	}
+
+	if err := scenario.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidScenario, err)
+	}
+	return &scenario, nil
+}
+
+// validate checks the scenario before any step is run. Fields referencing
+// variables are checked once expanded, when their step is run
+func (s *Scenario) validate() error {
+	for name := range s.Vars {
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, spec := range s.DIDs {
+		if !namePattern.MatchString(spec.Name) {
+			return fmt.Errorf("DID %d: invalid name %q", i+1, spec.Name)
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("DID %s is declared twice", spec.Name)
+		}
+		names[spec.Name] = true
+
+		if spec.DID != "" && (spec.Type != "" || spec.MasterDID != "" || spec.Localnet || spec.Tokens != 0) {
+			return fmt.Errorf("DID %s: type, master_did, localnet and tokens only apply to created DIDs", spec.Name)
+		}
+		if spec.Type != "" {
+			if _, err := did.ParseType(spec.Type); err != nil {
+				return fmt.Errorf("DID %s: %w", spec.Name, err)
+			}
+		}
+		if spec.Tokens != 0 && !spec.Localnet {
+			return fmt.Errorf("DID %s: tokens can only be used with localnet", spec.Name)
+		}
+	}
+
+	if len(s.Steps) == 0 {
+		return errors.New("no steps")
+	}
+	for i, step := range s.Steps {
+		if step == nil {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("%s #%d", step.action(), i+1)
+		}
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// validate checks the action and assertions of the step
+func (s *Step) validate() error {
+	actions := 0
+	for _, set := range []bool{s.Deploy != nil, s.Execute != nil, s.Faucet != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return errors.New("exactly one of deploy, execute and faucet is required")
+	}
+
+	switch {
+	case s.Deploy != nil:
+		d := s.Deploy
+		prebuilt := d.Wasm != "" || d.Source != "" || d.State != ""
+		if prebuilt && (d.Wasm == "" || d.Source == "" || d.State == "") {
+			return errors.New("wasm, source and state must be set together")
+		}
+		if !prebuilt && d.ContractDir == "" {
+			return errors.New("contract_dir is required")
+		}
+		if d.Amount != nil && *d.Amount <= 0 {
+			return errors.New("amount must be positive")
+		}
+	case s.Execute != nil:
+		e := s.Execute
+		if e.ContractDir == "" {
+			return errors.New("contract_dir is required")
+		}
+		if (e.Message == nil) == (e.MessageFile == "") {
+			return errors.New("exactly one of message and message_file is required")
+		}
+	case s.Faucet != nil:
+		if s.Faucet.Tokens < 1 {
+			return errors.New("tokens must be at least 1")
+		}
+	}
+
+	for name := range s.Capture {
+		if !namePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name %q", name)
+		}
+	}
+	if s.ExpectError != "" && (len(s.Expect) > 0 || len(s.Capture) > 0) {
+		return errors.New("expect and capture cannot be used with expect_error")
+	}
+	return nil
+}
+
+// action returns the name of the action of the step
+func (s *Step) action() string {
+	switch {
+	case s.Deploy != nil:
+		return "deploy"
+	case s.Execute != nil:
+		return "execute"
+	case s.Faucet != nil:
+		return "faucet"
+	default:
+		return "step"
+	}
+}
+
+// path resolves a path of the scenario relative to its directory
+func (s *Scenario) path(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
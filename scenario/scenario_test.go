@@ -0,0 +1,159 @@
+package scenario
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadScenario writes the scenario to a temporary file and loads it
+func loadScenario(t *testing.T, content string) (*Scenario, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoad(t *testing.T) {
+	scenario, err := loadScenario(t, `
+vars:
+  amount: "2"
+dids:
+  - name: alice
+    localnet: true
+    tokens: 10
+  - name: bob
+    did: bafybob
+steps:
+  - deploy:
+      contract_dir: ./counter
+      deployer: alice
+    capture:
+      hash: contract_hash
+  - name: increment
+    execute:
+      contract_dir: ./counter
+      contract_hash: ${hash}
+      message:
+        increment: {by: 2}
+    expect:
+      contract_result: {count: 2}
+`)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if scenario.Name != "scenario.yaml" {
+		t.Errorf("Name = %q, want the file name", scenario.Name)
+	}
+	if len(scenario.DIDs) != 2 || scenario.DIDs[0].Tokens != 10 || scenario.DIDs[1].DID != "bafybob" {
+		t.Errorf("DIDs = %+v", scenario.DIDs)
+	}
+	if names := scenario.Steps[0].Name + ", " + scenario.Steps[1].Name; names != "deploy #1, increment" {
+		t.Errorf("step names = %s", names)
+	}
+	if path := scenario.path(scenario.Steps[0].Deploy.ContractDir); path != filepath.Join(scenario.dir, "counter") {
+		t.Errorf("contract_dir resolved to %s", path)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "no steps",
+			content: "name: empty\n",
+			wantErr: "no steps",
+		},
+		{
+			name:    "unknown field",
+			content: "steps:\n  - deploy:\n      contract_dir: .\n      amout: 1\n",
+			wantErr: "field amout not found",
+		},
+		{
+			name:    "no action",
+			content: "steps:\n  - name: nothing\n    expect_error: failed\n",
+			wantErr: "step nothing: exactly one of deploy, execute and faucet is required",
+		},
+		{
+			name:    "two actions",
+			content: "steps:\n  - deploy:\n      contract_dir: .\n    faucet:\n      tokens: 1\n",
+			wantErr: "exactly one of deploy, execute and faucet is required",
+		},
+		{
+			name:    "deploy without contract_dir",
+			content: "steps:\n  - deploy:\n      deployer: alice\n",
+			wantErr: "step deploy #1: contract_dir is required",
+		},
+		{
+			name:    "incomplete prebuilt files",
+			content: "steps:\n  - deploy:\n      wasm: a.wasm\n      source: lib.rs\n",
+			wantErr: "wasm, source and state must be set together",
+		},
+		{
+			name:    "non-positive amount",
+			content: "steps:\n  - deploy:\n      contract_dir: .\n      amount: 0\n",
+			wantErr: "amount must be positive",
+		},
+		{
+			name:    "execute with message and message_file",
+			content: "steps:\n  - execute:\n      contract_dir: .\n      message_file: msg.json\n      message: {add: {}}\n",
+			wantErr: "exactly one of message and message_file is required",
+		},
+		{
+			name:    "execute without message",
+			content: "steps:\n  - execute:\n      contract_dir: .\n",
+			wantErr: "exactly one of message and message_file is required",
+		},
+		{
+			name:    "faucet without tokens",
+			content: "steps:\n  - faucet:\n      did: alice\n",
+			wantErr: "tokens must be at least 1",
+		},
+		{
+			name:    "expect_error with capture",
+			content: "steps:\n  - faucet:\n      tokens: 1\n    expect_error: failed\n    capture:\n      id: request_id\n",
+			wantErr: "expect and capture cannot be used with expect_error",
+		},
+		{
+			name:    "invalid capture name",
+			content: "steps:\n  - faucet:\n      tokens: 1\n    capture:\n      env.x: request_id\n",
+			wantErr: `invalid variable name "env.x"`,
+		},
+		{
+			name:    "DID declared twice",
+			content: "dids:\n  - name: alice\n  - name: alice\nsteps:\n  - faucet:\n      tokens: 1\n",
+			wantErr: "DID alice is declared twice",
+		},
+		{
+			name:    "existing DID with creation options",
+			content: "dids:\n  - name: alice\n    did: bafyalice\n    localnet: true\nsteps:\n  - faucet:\n      tokens: 1\n",
+			wantErr: "only apply to created DIDs",
+		},
+		{
+			name:    "tokens without localnet",
+			content: "dids:\n  - name: alice\n    tokens: 5\nsteps:\n  - faucet:\n      tokens: 1\n",
+			wantErr: "tokens can only be used with localnet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadScenario(t, tt.content)
+			if !errors.Is(err, ErrInvalidScenario) {
+				t.Fatalf("Load() error = %v, want %v", err, ErrInvalidScenario)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	// envPrefix prefixes the references to environment variables, ${env.NAME}
+	envPrefix = "env."
+	// didsPrefix prefixes the references to the DIDs of the scenario, ${dids.name}
+	didsPrefix = "dids."
+)
+
+// referencePattern matches the variable references of a string
+var referencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// variables are the values referenced by the steps of a running scenario
+type variables struct {
+	vars map[string]string
+	dids map[string]string
+}
+
+// lookup returns the value of a reference
+func (v *variables) lookup(name string) (string, error) {
+	switch {
+	case strings.HasPrefix(name, envPrefix):
+		if value, ok := os.LookupEnv(strings.TrimPrefix(name, envPrefix)); ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrInvalidScenario, strings.TrimPrefix(name, envPrefix))
+	case strings.HasPrefix(name, didsPrefix):
+		if value, ok := v.dids[strings.TrimPrefix(name, didsPrefix)]; ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("%w: DID %s is not declared", ErrInvalidScenario, strings.TrimPrefix(name, didsPrefix))
+	default:
+		if value, ok := v.vars[name]; ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("%w: variable %s is not defined", ErrInvalidScenario, name)
+	}
+}
+
+// expand replaces the references of s by their value
+func (v *variables) expand(s string) (string, error) {
+	var lookupErr error
+	expanded := referencePattern.ReplaceAllStringFunc(s, func(reference string) string {
+		value, err := v.lookup(referencePattern.FindStringSubmatch(reference)[1])
+		if err != nil && lookupErr == nil {
+			lookupErr = err
+		}
+		return value
+	})
+	return expanded, lookupErr
+}
+
+// expandAll expands the strings pointed to by fields
+func (v *variables) expandAll(fields ...*string) error {
+	for _, field := range fields {
+		expanded, err := v.expand(*field)
+		if err != nil {
+			return err
+		}
+		*field = expanded
+	}
+	return nil
+}
+
+// expandValue expands the strings of a value decoded from YAML
+func (v *variables) expandValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return v.expand(value)
+	case map[string]interface{}:
+		expanded := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			var err error
+			if expanded[key], err = v.expandValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	case []interface{}:
+		expanded := make([]interface{}, len(value))
+		for i, item := range value {
+			var err error
+			if expanded[i], err = v.expandValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	default:
+		return value, nil
+	}
+}
+
+// field returns the value of a field of the JSON encoding of result, with
+// nested fields separated by dots
+func field(result interface{}, path string) (interface{}, error) {
+	content, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil, err
+	}
+
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: result has no field %s", ErrInvalidScenario, path)
+		}
+		if value, ok = object[name]; !ok {
+			return nil, fmt.Errorf("%w: result has no field %s", ErrInvalidScenario, path)
+		}
+	}
+	return value, nil
+}
+
+// formatValue formats a captured value, strings as they are and other
+// values as JSON
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	content, _ := json.Marshal(value)
+	return string(content)
+}
+
+// matches reports whether the actual value of a field is the expected one.
+// A string field, such as a contract result, is parsed as JSON when the
+// expected value is not a string
+func matches(expected interface{}, actual interface{}) bool {
+	if s, ok := actual.(string); ok {
+		if _, ok := expected.(string); !ok {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+				return false
+			}
+			actual = parsed
+		}
+	}
+
+	// Compare the JSON encodings, so that YAML and JSON numbers match
+	var normalized [2]interface{}
+	for i, value := range []interface{}{expected, actual} {
+		content, err := json.Marshal(value)
+		if err != nil {
+			return false
+		}
+		if err := json.Unmarshal(content, &normalized[i]); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(normalized[0], normalized[1])
+}
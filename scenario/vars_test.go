@@ -0,0 +1,157 @@
+package scenario
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rubixchain/rubix-nexus/contract"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("NEXUS_TEST_AMOUNT", "5")
+	vars := &variables{
+		vars: map[string]string{"hash": "QmHash", "my-var": "x"},
+		dids: map[string]string{"alice": "bafyalice"},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "no reference", input: "plain $hash {hash}", want: "plain $hash {hash}"},
+		{name: "variable", input: "${hash}", want: "QmHash"},
+		{name: "variable with hyphen", input: "${my-var}", want: "x"},
+		{name: "several references", input: "${dids.alice}:${hash}:${env.NEXUS_TEST_AMOUNT}", want: "bafyalice:QmHash:5"},
+		{name: "environment variable", input: "amount ${env.NEXUS_TEST_AMOUNT}", want: "amount 5"},
+		{name: "DID", input: "${dids.alice}", want: "bafyalice"},
+		{name: "undefined variable", input: "${missing}", wantErr: "variable missing is not defined"},
+		{name: "unset environment variable", input: "${env.NEXUS_TEST_UNSET}", wantErr: "environment variable NEXUS_TEST_UNSET is not set"},
+		{name: "undeclared DID", input: "${dids.bob}", wantErr: "DID bob is not declared"},
+		{name: "first error reported", input: "${hash} ${missing} ${dids.bob}", wantErr: "variable missing is not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vars.expand(tt.input)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidScenario) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expand() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandValue(t *testing.T) {
+	vars := &variables{vars: map[string]string{"owner": "alice"}, dids: map[string]string{"bob": "bafybob"}}
+
+	var message interface{}
+	if err := yaml.Unmarshal([]byte("transfer:\n  from: ${owner}\n  to: ['${dids.bob}', carol]\n  amount: 2\n"), &message); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := vars.expandValue(message)
+	if err != nil {
+		t.Fatalf("expandValue() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"transfer": map[string]interface{}{
+			"from":   "alice",
+			"to":     []interface{}{"bafybob", "carol"},
+			"amount": 2,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandValue() = %#v, want %#v", got, want)
+	}
+
+	if _, err := vars.expandValue(map[string]interface{}{"a": []interface{}{"${missing}"}}); !errors.Is(err, ErrInvalidScenario) {
+		t.Errorf("expandValue() with an undefined reference error = %v", err)
+	}
+}
+
+func TestField(t *testing.T) {
+	result := &contract.ExecutionResult{
+		ContractResult: `{"count": 2}`,
+		Success:        true,
+		Preflight:      &contract.PreflightResult{Status: contract.PreflightPassed},
+		RequestID:      "req-1",
+	}
+
+	tests := []struct {
+		path    string
+		want    interface{}
+		wantErr bool
+	}{
+		{path: "request_id", want: "req-1"},
+		{path: "success", want: true},
+		{path: "preflight.status", want: "passed"},
+		{path: "contract_result", want: `{"count": 2}`},
+		{path: "missing", wantErr: true},
+		{path: "preflight.missing", wantErr: true},
+		{path: "request_id.nested", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := field(result, tt.path)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidScenario) {
+					t.Errorf("field() error = %v, want %v", err, ErrInvalidScenario)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("field() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("field() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	var expect map[string]interface{}
+	if err := yaml.Unmarshal([]byte("int: 2\nfloat: 1.5\nstring: hello\nnumeric_string: '2'\nobject: {count: 2, tags: [a]}\n"), &expect); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		want     bool
+	}{
+		{name: "YAML int and JSON number", expected: expect["int"], actual: float64(2), want: true},
+		{name: "YAML float and JSON number", expected: expect["float"], actual: 1.5, want: true},
+		{name: "YAML int and other number", expected: expect["int"], actual: 2.5},
+		{name: "string", expected: expect["string"], actual: "hello", want: true},
+		{name: "YAML int and JSON result", expected: expect["int"], actual: "2", want: true},
+		{name: "string and JSON number", expected: expect["numeric_string"], actual: float64(2)},
+		{name: "string compared as is", expected: expect["numeric_string"], actual: "2", want: true},
+		{name: "YAML object and JSON result", expected: expect["object"], actual: `{"tags": ["a"], "count": 2.0}`, want: true},
+		{name: "YAML object and other result", expected: expect["object"], actual: `{"tags": ["b"], "count": 2}`},
+		{name: "YAML int and result that is not JSON", expected: expect["int"], actual: "two"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("matches(%#v, %#v) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,55 @@
 package utils
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
 
 // FileExists checks if a file exists at the given path
 func FileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// WriteFileAtomic writes the file through a temporary file renamed over it,
+// so that an interrupted write never leaves a truncated file
+func WriteFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmpName, err := WriteTempFile(path, content, perm)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteTempFile writes the content to a temporary file with the given
+// permissions next to path, creating its directory, and returns its name
+func WriteTempFile(path string, content []byte, perm os.FileMode) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	// Temporary files are only readable by their owner, set the permissions
+	// of the file explicitly
+	err = tmp.Chmod(perm)
+	if err == nil {
+		_, err = tmp.Write(content)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return tmp.Name(), nil
+}